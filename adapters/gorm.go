@@ -1,6 +1,7 @@
 package adapters
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
@@ -23,41 +24,142 @@ func NewGorm(db *gorm.DB) *Gorm {
 // LoadList loads records from db.
 // Pass slice, not adress and list params.
 func (adapter *Gorm) LoadList(recordsPtr interface{}, params *list_params.ListParams, table string) error {
-	str, arguments := params.GetWhereCondition()
+	return adapter.LoadListContext(params.Context(), recordsPtr, params, table)
+}
+
+// LoadListContext loads records from db as LoadList does.
+// The context is passed to context-aware custom filters, sortings and includes
+func (adapter *Gorm) LoadListContext(ctx context.Context, recordsPtr interface{}, params *list_params.ListParams, table string) error {
+	params.SetContext(ctx)
+	str, arguments, err := params.GetWhereConditionWithError()
+	if err != nil {
+		return err
+	}
 	query := adapter.db.Where(str, arguments...)
 
-	query = query.Order(params.GetOrderByString())
+	order, err := params.GetOrderByStringWithError()
+	if err != nil {
+		return err
+	}
+	query = query.Order(order)
 
 	if params.GetLimit() != 0 {
 		query = query.Limit(params.GetLimit())
 	}
 	query = query.Offset(params.GetOffset())
 
-	query = query.Joins(params.GetJoinCondition())
+	params.SetDialect(list_params.Dialect(adapter.db.Dialect().GetName()))
+	joinCondition, joinArguments, err := params.GetJoinConditionWithArgs()
+	if err != nil {
+		return err
+	}
+	query = query.Joins(joinCondition, joinArguments...)
 
-	for _, preloadName := range params.GetPreloads() {
-		query = query.Preload(preloadName)
+	preloads, err := params.GetPreloadsWithConditionsWithError()
+	if err != nil {
+		return err
+	}
+	for _, preload := range preloads {
+		query = query.Preload(preload.Name, preloadConditions(preload))
 	}
 
 	selectQuery := transformSelectQuery(params.GetSelectQuery(), params.ObjectType, table)
+	selectQuery = append(selectQuery, params.GetCountSelects()...)
 	query = query.Select(selectQuery)
 	if err := query.Table(table).Find(recordsPtr).Error; err != nil {
 		return err
 	}
 
-	slice := reflect.ValueOf(recordsPtr).Elem()
-	recordsSlice := make([]interface{}, slice.Len())
-	for i := 0; i < slice.Len(); i++ {
-		recordsSlice[i] = slice.Index(i).Interface()
+	return params.LoadIncludes(ctx, recordsPtr)
+}
+
+// LoadAggregates loads rows of grouped list with values of requested groups and aggregates.
+// Example: group=currency&aggregate=sum(amount),count(*)&having[sum_amount:gt]=1000.
+// Empty buckets of time groups are filled, see FillTimeBuckets
+func (adapter *Gorm) LoadAggregates(params *list_params.ListParams, table string) ([]list_params.AggregateRow, error) {
+	return adapter.LoadAggregatesContext(params.Context(), params, table)
+}
+
+// LoadAggregatesContext loads rows of grouped list as LoadAggregates does.
+// The context is passed to context-aware custom filters and sortings
+func (adapter *Gorm) LoadAggregatesContext(ctx context.Context, params *list_params.ListParams, table string) (
+	[]list_params.AggregateRow, error) {
+	params.SetContext(ctx)
+	str, arguments, err := params.GetWhereConditionWithError()
+	if err != nil {
+		return nil, err
+	}
+	query := adapter.db.Table(table).Where(str, arguments...)
+
+	order, err := params.GetOrderByStringWithError()
+	if err != nil {
+		return nil, err
 	}
+	query = query.Order(order)
 
-	for _, customIncludesFunc := range params.GetCustomIncludesFunctions() {
-		if err := customIncludesFunc(recordsSlice); err != nil {
-			return err
+	// aggregates are not paginated by default page size, time buckets are filled for all rows
+	if params.HasPageSize() {
+		query = query.Limit(params.GetLimit()).Offset(params.GetOffset())
+	}
+
+	params.SetDialect(list_params.Dialect(adapter.db.Dialect().GetName()))
+	joinCondition, joinArguments, err := params.GetJoinConditionWithArgs()
+	if err != nil {
+		return nil, err
+	}
+	query = query.Joins(joinCondition, joinArguments...)
+	query = groupQuery(query, params)
+
+	rows, err := query.Select(params.GetAggregateSelects()).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]list_params.AggregateRow, 0)
+	for rows.Next() {
+		values := make([]interface{}, len(params.Groups)+len(params.Aggregates))
+		pointers := make([]interface{}, len(values))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
 		}
+		row, err := params.ScanAggregateRow(values)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
+	return params.FillTimeBuckets(result)
+}
+
+// groupQuery applies group by statement of requested groups and having filters
+func groupQuery(query *gorm.DB, params *list_params.ListParams) *gorm.DB {
+	if groupBy := params.GetAggregateGroupBy(); groupBy != "" {
+		query = query.Group(groupBy)
+	}
+	if having, arguments := params.GetHavingCondition(); having != "" {
+		query = query.Having(having, arguments...)
+	}
+	return query
+}
 
-	return nil
+// preloadConditions returns gorm preload function applying conditions of included relation scope
+func preloadConditions(preload list_params.Preload) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if preload.Where != "" {
+			db = db.Where(preload.Where, preload.Args...)
+		}
+		if preload.Order != "" {
+			db = db.Order(preload.Order)
+		}
+		return db
+	}
 }
 
 func transformSelectQuery(paramsQuery []string, modelType reflect.Type, table string) []string {
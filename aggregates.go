@@ -0,0 +1,453 @@
+package list_params
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+const groupParameter = "group"
+const aggregateParameter = "aggregate"
+const countAllField = "*"
+
+// AggregateFunc is SQL aggregate function
+type AggregateFunc string
+
+const (
+	AggregateCount = AggregateFunc("count")
+	AggregateSum   = AggregateFunc("sum")
+	AggregateAvg   = AggregateFunc("avg")
+	AggregateMin   = AggregateFunc("min")
+	AggregateMax   = AggregateFunc("max")
+)
+
+var (
+	knownAggregateFuncs = map[string]AggregateFunc{
+		"count": AggregateCount,
+		"sum":   AggregateSum,
+		"avg":   AggregateAvg,
+		"min":   AggregateMin,
+		"max":   AggregateMax,
+	}
+	aggregatePattern = regexp.MustCompile(`^(\w+)\(([\w.]+|\*)\)(:(\w+))?$`)
+	floatType        = reflect.TypeOf(float64(0))
+	stringType       = reflect.TypeOf("")
+)
+
+// Aggregate is aggregate function of field requested by aggregate param
+type Aggregate struct {
+	Func  AggregateFunc
+	Field string // Field of current or nested model or * for count of records
+	Alias string // Name in result rows, sortings and having filters. Example: sum_amount
+}
+
+// AggregateRow is row of grouped list with values of group fields by field names and values of aggregates by aliases.
+// Values have types of model fields, counts are int64, averages of integer fields are float64.
+// Sums and averages of decimal and numeric columns are strings to keep precision
+type AggregateRow struct {
+	Groups     map[string]interface{} `json:"groups"`
+	Aggregates map[string]interface{} `json:"aggregates"`
+}
+
+// AllowGroups allows grouping by fields. Example: group=currency,status
+func (params *ListParams) AllowGroups(fields []string) {
+	params.allowedListParams.Groups = append([]string(nil), fields...)
+}
+
+// AllowAggregates allows aggregate functions in format function(field).
+// Functions are count, sum, avg, min and max, count(*) counts records of the group.
+// Example: aggregate=sum(amount),count(*) returns sum_amount and count.
+// Alias can be passed after colon: aggregate=sum(amount):total
+func (params *ListParams) AllowAggregates(aggregates []string) {
+	allowed := make([]Aggregate, len(aggregates))
+	for i, v := range aggregates {
+		aggregate, ok := parseAggregate(v)
+		if !ok {
+			panic(fmt.Errorf("Invalid aggregate %s", v))
+		}
+		allowed[i] = aggregate
+	}
+	params.allowedListParams.Aggregates = allowed
+}
+
+// IsGrouped returns true if groups or aggregates are requested.
+// Grouped lists should be loaded as rows, see GetAggregateSelects and ScanAggregateRow
+func (params *ListParams) IsGrouped() bool {
+	return len(params.Groups) != 0 || len(params.Aggregates) != 0
+}
+
+// GetAggregateSelects returns select expressions of requested groups and aggregates.
+// Example: transactions.currency AS currency, SUM(transactions.amount) AS sum_amount.
+// Time groups are rendered by dialect, see SetDialect and Dialect.TimeBucket
+func (params *ListParams) GetAggregateSelects() []string {
+	result := make([]string, 0, len(params.Groups)+len(params.Aggregates))
+	for _, group := range params.Groups {
+		field, _, _ := parseGroup(group)
+		result = append(result, fmt.Sprintf("%s AS %s", params.getGroupColumn(group), getGroupAlias(field)))
+	}
+	for _, aggregate := range params.Aggregates {
+		result = append(result, fmt.Sprintf("%s AS %s", params.getAggregateExpression(&aggregate), aggregate.Alias))
+	}
+	return result
+}
+
+// GetAggregateGroupBy returns columns of requested groups for group by statement.
+// Time groups are rendered by dialect as GetAggregateSelects does
+func (params *ListParams) GetAggregateGroupBy() string {
+	columns := make([]string, len(params.Groups))
+	for i, group := range params.Groups {
+		columns[i] = params.getGroupColumn(group)
+	}
+	return strings.Join(columns, ", ")
+}
+
+// GetHavingCondition returns sql string with params for having statement by having filters.
+// Arguments are returned one per placeholder. Example: having[sum_amount:gt]=1000
+func (params *ListParams) GetHavingCondition() (string, []interface{}) {
+	conditions := make([]string, 0, len(params.Havings))
+	arguments := make([]interface{}, 0, len(params.Havings))
+	for _, having := range params.Havings {
+		aggregate := params.getAggregateByAlias(having.Field)
+		if aggregate == nil {
+			continue
+		}
+		values, err := params.getHavingValues(&having, aggregate)
+		if err != nil {
+			values = stringsToInterfaces(having.Values)
+		}
+		condition, args := operations[having.Operator](params.getAggregateExpression(aggregate), values)
+		if condition == "" {
+			continue
+		}
+		conditions = append(conditions, "("+condition+")")
+		arguments = append(arguments, placeholderArguments(condition, args)...)
+	}
+	return strings.Join(conditions, " AND "), arguments
+}
+
+// ScanAggregateRow returns row of grouped list from values scanned
+// in order of GetAggregateSelects and converts them to types of model fields.
+// Time groups are returned as start of the bucket in timezone of params, see SetTimezone
+func (params *ListParams) ScanAggregateRow(values []interface{}) (AggregateRow, error) {
+	row := AggregateRow{
+		Groups:     make(map[string]interface{}, len(params.Groups)),
+		Aggregates: make(map[string]interface{}, len(params.Aggregates)),
+	}
+	if len(values) != len(params.Groups)+len(params.Aggregates) {
+		return row, fmt.Errorf("Expected %d values of aggregate row, got %d",
+			len(params.Groups)+len(params.Aggregates), len(values))
+	}
+	for i, group := range params.Groups {
+		field, bucket, _ := parseGroup(group)
+		if bucket != "" {
+			row.Groups[field] = parseBucketTime(values[i], params.GetTimezone())
+			continue
+		}
+		fieldType, _ := params.getFieldType(field)
+		row.Groups[field] = convertScannedValue(fieldType, values[i])
+	}
+	for i, aggregate := range params.Aggregates {
+		row.Aggregates[aggregate.Alias] = convertScannedValue(params.getAggregateType(&aggregate),
+			values[len(params.Groups)+i])
+	}
+	return row, nil
+}
+
+// setGroups takes fields requested by group param.
+// Time fields can be grouped by buckets: group=created_at:day, see TimeBucket
+func (params *ListParams) setGroups(values url.Values) {
+	groups := make([]string, 0)
+	for _, value := range values[groupParameter] {
+		for _, field := range strings.Split(value, queryParamDelimiter) {
+			if field != "" {
+				groups = append(groups, field)
+			}
+		}
+	}
+	params.Groups = groups
+}
+
+// setAggregates takes aggregates requested by aggregate param. Invalid aggregates are added to errors
+func (params *ListParams) setAggregates(values url.Values) {
+	aggregates := make([]Aggregate, 0)
+	for _, value := range values[aggregateParameter] {
+		for _, v := range strings.Split(value, queryParamDelimiter) {
+			if v == "" {
+				continue
+			}
+			aggregate, ok := parseAggregate(v)
+			if !ok {
+				params.addError(newParamError(CodeAggregateInvalid, aggregateParameter, "", "", v, nil))
+				continue
+			}
+			aggregates = append(aggregates, aggregate)
+		}
+	}
+	params.Aggregates = aggregates
+}
+
+// setHavings takes having filters by aliases of aggregates. Example: having[sum_amount:gt]=1000
+func (params *ListParams) setHavings(values url.Values) {
+	list := make([]FilterListParameter, 0)
+	pattern := regexp.MustCompile(`^having\[(.*)\]$`)
+	for k, v := range values {
+		submatches := pattern.FindStringSubmatch(k)
+		if len(submatches) < 2 || len(v) == 0 {
+			continue
+		}
+		field, operator := params.parseField(submatches[1])
+		pair := FieldOperatorPair{Field: field, Operator: operator}
+		list = append(list, FilterListParameter{FieldOperatorPair: pair,
+			Values: strings.Split(v[0], queryParamDelimiter)})
+	}
+	params.Havings = list
+}
+
+// validateAggregates checks that requested groups and aggregates are allowed
+// and having filters refer to requested aggregates
+func (params *ListParams) validateAggregates() {
+	for _, group := range params.Groups {
+		field, bucket, ok := parseGroup(group)
+		if !params.isAllowedGroup(field) {
+			params.addError(newParamError(CodeGroupNotAllowed, groupParameter, field, "", "", nil))
+			continue
+		}
+		if !ok || (bucket != "" && !params.isTimeField(field)) {
+			params.addError(newParamError(CodeGroupBucketInvalid, groupParameter, field, "", group, nil))
+		}
+	}
+	for _, aggregate := range params.Aggregates {
+		if !params.isAllowedAggregate(&aggregate) {
+			params.addError(newParamError(CodeAggregateNotAllowed, aggregateParameter,
+				formatAggregate(&aggregate), "", "", nil))
+		}
+	}
+	for _, having := range params.Havings {
+		aggregate := params.getAggregateByAlias(having.Field)
+		if aggregate == nil {
+			params.addError(newParamError(CodeHavingNotAllowed, havingParameter(having.Field, having.Operator),
+				having.Field, having.Operator, "", nil))
+			continue
+		}
+		if _, err := params.getHavingValues(&having, aggregate); err != nil {
+			ruleErr := err.(*RuleError)
+			params.addError(newParamError(CodeFilterValueInvalid, havingParameter(having.Field, having.Operator),
+				having.Field, having.Operator, ruleErr.Value, ruleErr.Args))
+		}
+	}
+}
+
+// getAggregateByAlias returns requested aggregate by alias
+func (params *ListParams) getAggregateByAlias(alias string) *Aggregate {
+	for _, aggregate := range params.Aggregates {
+		if aggregate.Alias == alias {
+			return &aggregate
+		}
+	}
+	return nil
+}
+
+// getAggregateExpression returns SQL expression of aggregate. Example: SUM(transactions.amount)
+func (params *ListParams) getAggregateExpression(aggregate *Aggregate) string {
+	column := countAllField
+	if aggregate.Field != countAllField {
+		column = params.getFieldColumn(aggregate.Field)
+	}
+	return fmt.Sprintf("%s(%s)", strings.ToUpper(string(aggregate.Func)), column)
+}
+
+// getAggregateType returns type of aggregate value. Sums, min and max have type of the field,
+// averages of integer fields are float64. Sums and averages of decimal and numeric columns are strings
+func (params *ListParams) getAggregateType(aggregate *Aggregate) reflect.Type {
+	if aggregate.Func == AggregateCount {
+		return countType
+	}
+	field, ok := params.getStructField(aggregate.Field)
+	if !ok {
+		return nil
+	}
+	if aggregate.Func == AggregateSum || aggregate.Func == AggregateAvg {
+		if isDecimalColumn(field) {
+			return stringType
+		}
+		kind := indirectType(field.Type).Kind()
+		if aggregate.Func == AggregateAvg && kind >= reflect.Int && kind <= reflect.Uint64 {
+			return floatType
+		}
+	}
+	return field.Type
+}
+
+// getHavingValues returns values of having filter converted to type of the aggregate.
+// Returns *RuleError if some value can not be converted
+func (params *ListParams) getHavingValues(having *FilterListParameter, aggregate *Aggregate) ([]interface{}, error) {
+	valueType := params.getAggregateType(aggregate)
+	result := make([]interface{}, len(having.Values))
+	for i, v := range having.Values {
+		if valueType == nil {
+			result[i] = v
+			continue
+		}
+		converted, err := ConvertFilterValue(valueType, v)
+		if err != nil {
+			return nil, newRuleError(RuleType, v, map[string]string{"type": typeDescription(valueType)})
+		}
+		result[i] = converted
+	}
+	return result, nil
+}
+
+// getFieldColumn returns column of the field with table prefix or alias of joined relation
+func (params *ListParams) getFieldColumn(field string) string {
+	if column, _, ok := params.resolveRelationField(field); ok {
+		return column
+	}
+	column := params.transformName(field)
+	if len(strings.Split(column, sqlTableFieldDelimiter)) == 1 {
+		column = params.addTablePrefix(column)
+	}
+	return column
+}
+
+// isTimeField returns true if type of the field is time or unknown
+func (params *ListParams) isTimeField(field string) bool {
+	fieldType, ok := params.getFieldType(field)
+	return !ok || indirectType(fieldType) == timeType
+}
+
+func (params *ListParams) isAllowedGroup(field string) bool {
+	for _, v := range params.allowedListParams.Groups {
+		if v == field {
+			return true
+		}
+	}
+	return false
+}
+
+func (params *ListParams) isAllowedAggregate(aggregate *Aggregate) bool {
+	for _, v := range params.allowedListParams.Aggregates {
+		if v.Func == aggregate.Func && v.Field == aggregate.Field {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeAggregates returns aggregates in the format of aggregate param
+func (params *ListParams) encodeAggregates() string {
+	aggregates := make([]string, len(params.Aggregates))
+	for i, aggregate := range params.Aggregates {
+		aggregates[i] = formatAggregate(&aggregate)
+		if aggregate.Alias != getAggregateAlias(aggregate.Func, aggregate.Field) {
+			aggregates[i] += operatorDelimiter + aggregate.Alias
+		}
+	}
+	return strings.Join(aggregates, queryParamDelimiter)
+}
+
+// parseAggregate parses aggregate in format function(field) with optional alias after colon
+func parseAggregate(value string) (Aggregate, bool) {
+	submatches := aggregatePattern.FindStringSubmatch(value)
+	if len(submatches) < 5 {
+		return Aggregate{}, false
+	}
+	function, ok := knownAggregateFuncs[strings.ToLower(submatches[1])]
+	if !ok || (submatches[2] == countAllField && function != AggregateCount) {
+		return Aggregate{}, false
+	}
+	alias := submatches[4]
+	if alias == "" {
+		alias = getAggregateAlias(function, submatches[2])
+	}
+	return Aggregate{Func: function, Field: submatches[2], Alias: alias}, true
+}
+
+// placeholderArguments returns arguments of condition one per placeholder.
+// Values of condition with single placeholder are passed as one slice. Example: count IN (?)
+func placeholderArguments(condition string, args []interface{}) []interface{} {
+	if strings.Count(condition, "?") == len(args) {
+		return args
+	}
+	return []interface{}{args}
+}
+
+// getAggregateAlias returns default alias of aggregate. Example: sum_amount, count
+func getAggregateAlias(function AggregateFunc, field string) string {
+	if field == countAllField {
+		return string(function)
+	}
+	return string(function) + "_" + getGroupAlias(field)
+}
+
+// getGroupAlias returns alias of group field. Example: account__currency
+func getGroupAlias(field string) string {
+	return strings.Replace(strcase.ToSnake(field), sqlTableFieldDelimiter, relationAliasDelimiter, -1)
+}
+
+func formatAggregate(aggregate *Aggregate) string {
+	return fmt.Sprintf("%s(%s)", aggregate.Func, aggregate.Field)
+}
+
+func havingParameter(field string, operator Operator) string {
+	if operator == OperatorEq {
+		return fmt.Sprintf("having[%s]", field)
+	}
+	return fmt.Sprintf("having[%s]", filterWithOperator(field, operator))
+}
+
+// convertScannedValue converts value scanned from database to the type.
+// Values are returned as is if the type is unknown or the value can not be converted
+func convertScannedValue(valueType reflect.Type, value interface{}) interface{} {
+	if bytes, ok := value.([]byte); ok {
+		value = string(bytes)
+	}
+	if value == nil || valueType == nil {
+		return value
+	}
+	for valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+	v := reflect.ValueOf(value)
+	switch {
+	case v.Type() == valueType:
+		return value
+	case isNumericKind(v.Kind()) && isNumericKind(valueType.Kind()):
+		return v.Convert(valueType).Interface()
+	case v.Kind() == reflect.String:
+		if converted, err := ConvertFilterValue(valueType, value.(string)); err == nil {
+			return converted
+		}
+	case v.Type().ConvertibleTo(valueType) && v.Kind() == valueType.Kind():
+		return v.Convert(valueType).Interface()
+	}
+	return value
+}
+
+// isDecimalColumn returns true if the field is stored in decimal or numeric column by gorm type tag
+func isDecimalColumn(field reflect.StructField) bool {
+	columnType := strings.ToUpper(parseGormTag(field.Tag.Get("gorm"))["TYPE"])
+	return strings.HasPrefix(columnType, "DECIMAL") || strings.HasPrefix(columnType, "NUMERIC")
+}
+
+// zeroAggregateValue returns zero of the aggregate type. Returns nil if the type is unknown or not numeric
+func zeroAggregateValue(valueType reflect.Type) interface{} {
+	if valueType == nil {
+		return nil
+	}
+	valueType = indirectType(valueType)
+	switch {
+	case valueType == stringType:
+		return "0"
+	case isNumericKind(valueType.Kind()), valueType.Kind() == reflect.Struct && valueType != timeType:
+		return reflect.Zero(valueType).Interface()
+	}
+	return nil
+}
+
+func isNumericKind(kind reflect.Kind) bool {
+	return (kind >= reflect.Int && kind <= reflect.Uint64) || kind == reflect.Float32 || kind == reflect.Float64
+}
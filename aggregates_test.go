@@ -0,0 +1,107 @@
+package list_params
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+const aggregatesQuery = "group=currency&aggregate=count(*),sum(amount),avg(amount),sum(fee)"
+
+func newAggregateParams(query string) *ListParams {
+	params := NewListParamsFromQuery(aggregatesQuery+query, transaction{})
+	params.AllowGroups([]string{"currency"})
+	params.AllowAggregates([]string{"count(*)", "sum(amount)", "avg(amount)", "sum(fee)"})
+	return params
+}
+
+func TestAggregateSelects(t *testing.T) {
+	params := newAggregateParams("")
+	if ok, errs := params.Validate(); !ok {
+		t.Fatal(errs)
+	}
+
+	expected := []string{"transactions.currency AS currency", "COUNT(*) AS count",
+		"SUM(transactions.amount) AS sum_amount", "AVG(transactions.amount) AS avg_amount",
+		"SUM(transactions.fee) AS sum_fee"}
+	if selects := params.GetAggregateSelects(); !reflect.DeepEqual(selects, expected) {
+		t.Errorf("expected %v, got %v", expected, selects)
+	}
+	if groupBy := params.GetAggregateGroupBy(); groupBy != "transactions.currency" {
+		t.Errorf("expected group by currency, got %s", groupBy)
+	}
+}
+
+func TestAggregateTypes(t *testing.T) {
+	params := newAggregateParams("")
+	expected := map[string]reflect.Type{
+		"count":      reflect.TypeOf(int64(0)),
+		"sum_amount": reflect.TypeOf(int64(0)),
+		"avg_amount": floatType,
+		"sum_fee":    stringType,
+	}
+	for _, aggregate := range params.Aggregates {
+		if aggregateType := params.getAggregateType(&aggregate); aggregateType != expected[aggregate.Alias] {
+			t.Errorf("%s: expected %s, got %s", aggregate.Alias, expected[aggregate.Alias], aggregateType)
+		}
+	}
+}
+
+func TestHavingCondition(t *testing.T) {
+	tests := []struct {
+		name      string
+		havings   []FilterListParameter
+		condition string
+		arguments string
+	}{
+		{"between", []FilterListParameter{
+			{FieldOperatorPair{"sum_amount", OperatorGte}, []string{"10"}},
+			{FieldOperatorPair{"sum_amount", OperatorLte}, []string{"20"}},
+		}, "(SUM(transactions.amount) >= ?) AND (SUM(transactions.amount) <= ?)", "[10 20]"},
+		{"in", []FilterListParameter{
+			{FieldOperatorPair{"count", OperatorIn}, []string{"1", "2"}},
+		}, "(COUNT(*) IN (?))", "[[1 2]]"},
+		{"equal to any value", []FilterListParameter{
+			{FieldOperatorPair{"avg_amount", OperatorEq}, []string{"1.5", "2"}},
+		}, "(AVG(transactions.amount) = ? OR AVG(transactions.amount) = ?)", "[1.5 2]"},
+		{"in and greater", []FilterListParameter{
+			{FieldOperatorPair{"count", OperatorNin}, []string{"3", "4"}},
+			{FieldOperatorPair{"sum_fee", OperatorGt}, []string{"0.5"}},
+		}, "(COUNT(*) NOT IN (?)) AND (SUM(transactions.fee) > ?)", "[[3 4] 0.5]"},
+		{"unknown alias", []FilterListParameter{
+			{FieldOperatorPair{"max_amount", OperatorGt}, []string{"1"}},
+		}, "", "[]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			params := newAggregateParams("")
+			params.Havings = test.havings
+
+			condition, arguments := params.GetHavingCondition()
+			if condition != test.condition {
+				t.Errorf("expected %s, got %s", test.condition, condition)
+			}
+			if fmt.Sprint(arguments) != test.arguments {
+				t.Errorf("expected arguments %s, got %v", test.arguments, arguments)
+			}
+		})
+	}
+}
+
+func TestHavingByUnknownAliasIsRejected(t *testing.T) {
+	params := newAggregateParams("&having[max_amount:gt]=1")
+	if ok, errs := params.Validate(); ok {
+		t.Errorf("expected error of unknown aggregate, got %v", errs)
+	}
+}
+
+func TestAggregatesNotAllowed(t *testing.T) {
+	params := NewListParamsFromQuery(aggregatesQuery, transaction{})
+	params.AllowGroups([]string{"status"})
+	params.AllowAggregates([]string{"count(*)"})
+
+	ok, errs := params.Validate()
+	if ok || len(errs) != 4 {
+		t.Errorf("expected errors of group and 3 aggregates, got %v", errs)
+	}
+}
@@ -0,0 +1,109 @@
+package list_params
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+const countFieldName = "count"
+const countAliasSuffix = "_count"
+
+var countType = reflect.TypeOf(int64(0))
+
+// AddCountableRelation allows to filter and sort by count of related records
+// and to include the count into output fields.
+// Example: filter[cards.count:gt]=3, sort=-transactions.count, include=transactions.count.
+// Filters, sortings and includes of counts still have to be allowed.
+// Relation has to be has-one, has-many or many-to-many, counts of other relations are rejected by Validate
+func (params *ListParams) AddCountableRelation(relation string) {
+	params.countableRelations = append(params.countableRelations, relation)
+	params.Includes.addCountIncludes(relation + sqlTableFieldDelimiter + countFieldName)
+}
+
+// GetCountSelects returns select expressions of counts included by include param.
+// Example: (SELECT COUNT(*) FROM transactions AS rel_transactions WHERE ...) AS transactions_count
+func (params *ListParams) GetCountSelects() []string {
+	result := make([]string, 0)
+	for _, relation := range params.countableRelations {
+		if !params.Includes.isIncluded(relation + sqlTableFieldDelimiter + countFieldName) {
+			continue
+		}
+		if subquery, ok := params.getCountSubquery(relation); ok {
+			result = append(result, fmt.Sprintf("%s AS %s", subquery, getCountAlias(relation)))
+		}
+	}
+	return result
+}
+
+// getCountOutputFields returns names of included counts for output fields
+func (params *ListParams) getCountOutputFields() []interface{} {
+	result := make([]interface{}, 0)
+	for _, relation := range params.countableRelations {
+		if params.Includes.isIncluded(relation + sqlTableFieldDelimiter + countFieldName) {
+			result = append(result, getCountAlias(relation))
+		}
+	}
+	return result
+}
+
+// isCountableField returns false if the field is count of relation which can not be counted
+func (params *ListParams) isCountableField(field string) bool {
+	relationName, ok := params.getCountRelationName(field)
+	if !ok {
+		return true
+	}
+	_, ok = params.getCountSubquery(relationName)
+	return ok
+}
+
+// validateCountIncludes checks that included counts can be counted
+func (params *ListParams) validateCountIncludes() {
+	for _, relation := range params.countableRelations {
+		include := relation + sqlTableFieldDelimiter + countFieldName
+		for _, v := range params.Includes.passedIncludes {
+			if v == include && params.Includes.isAllowedIncludes(v) && !params.isCountableField(include) {
+				params.addError(newCountRelationError("include", include))
+			}
+		}
+	}
+}
+
+// getCountRelationName returns name of countable relation if the field is in format [relationship].count
+func (params *ListParams) getCountRelationName(field string) (string, bool) {
+	parts := strings.Split(field, sqlTableFieldDelimiter)
+	if len(parts) != 2 || parts[1] != countFieldName {
+		return "", false
+	}
+	for _, v := range params.countableRelations {
+		if v == parts[0] {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// getCountSubquery returns correlated subquery counting related records
+func (params *ListParams) getCountSubquery(relationName string) (string, bool) {
+	if params.ObjectType == nil {
+		return "", false
+	}
+	modelType := indirectType(params.ObjectType)
+	relation, ok := params.getRelation(modelType, relationName)
+	if !ok || relation.Kind == RelationBelongsTo {
+		return "", false
+	}
+	alias := params.relationAlias(relationName)
+	return fmt.Sprintf("(SELECT COUNT(*) FROM %s AS %s WHERE %s)",
+		relation.Table, alias, relation.onStatement(getTableName(modelType), alias)), true
+}
+
+func newCountRelationError(parameter, field string) *ParamError {
+	return newParamError(CodeCountNotAvailable, parameter, field, "", "", nil)
+}
+
+func getCountAlias(relation string) string {
+	return strings.Replace(strcase.ToSnake(relation), sqlTableFieldDelimiter, relationAliasDelimiter, -1) + countAliasSuffix
+}
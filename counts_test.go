@@ -0,0 +1,65 @@
+package list_params
+
+import (
+	"fmt"
+	"testing"
+)
+
+const transactionsCount = "(SELECT COUNT(*) FROM transactions AS rel_transactions " +
+	"WHERE rel_transactions.account_id = accounts.id)"
+
+func newCountParams(query string) *ListParams {
+	params := NewListParamsFromQuery(query, account{})
+	params.AddCountableRelation("transactions")
+	params.AllowFilters([]string{"transactions.count:gt"})
+	params.AllowSortings([]string{"transactions.count"})
+	params.AllowIncludes([]string{"transactions.count"})
+	return params
+}
+
+func TestCountFilterAndSorting(t *testing.T) {
+	params := newCountParams("filter[transactions.count:gt]=3&sort=-transactions.count")
+	if ok, errs := params.Validate(); !ok {
+		t.Fatal(errs)
+	}
+
+	where, arguments := params.GetWhereCondition()
+	if where != "("+transactionsCount+" > ?)" || fmt.Sprint(arguments) != "[[3]]" {
+		t.Errorf("expected condition by count, got %s %v", where, arguments)
+	}
+	if order := params.GetOrderByString(); order != transactionsCount+" DESC" {
+		t.Errorf("expected order by count, got %s", order)
+	}
+}
+
+func TestCountInclude(t *testing.T) {
+	params := newCountParams("include=transactions.count")
+	if ok, errs := params.Validate(); !ok {
+		t.Fatal(errs)
+	}
+
+	expected := "[" + transactionsCount + " AS transactions_count]"
+	if selects := fmt.Sprint(params.GetCountSelects()); selects != expected {
+		t.Errorf("expected %s, got %s", expected, selects)
+	}
+	if preloads := params.GetPreloads(); len(preloads) != 0 {
+		t.Errorf("expected count not to be preloaded, got %v", preloads)
+	}
+}
+
+func TestCountOfBelongsToRelationIsRejected(t *testing.T) {
+	params := NewListParamsFromQuery("filter[user.count:gt]=1&include=user.count", account{})
+	params.AddCountableRelation("user")
+	params.AllowFilters([]string{"user.count:gt"})
+	params.AllowIncludes([]string{"user.count"})
+
+	ok, errs := params.Validate()
+	if ok || len(errs) != 2 {
+		t.Fatalf("expected errors of filter and include, got %v", errs)
+	}
+	for _, err := range errs {
+		if code := AsParamError(err).Code; code != CodeCountNotAvailable {
+			t.Errorf("expected %s error, got %s", CodeCountNotAvailable, code)
+		}
+	}
+}
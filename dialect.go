@@ -0,0 +1,112 @@
+package list_params
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Dialect is name of SQL dialect. Names match names of gorm dialects
+type Dialect string
+
+const (
+	DialectMySQL    = Dialect("mysql")
+	DialectPostgres = Dialect("postgres")
+	DialectSQLite   = Dialect("sqlite3")
+	DialectMSSQL    = Dialect("mssql")
+)
+
+var (
+	mysqlBucketFormats = map[TimeBucket]string{
+		BucketHour:  "%Y-%m-%d %H:00:00",
+		BucketDay:   "%Y-%m-%d",
+		BucketWeek:  "%Y-%m-%d",
+		BucketMonth: "%Y-%m-01",
+		BucketYear:  "%Y-01-01",
+	}
+	sqliteBucketFormats = map[TimeBucket]string{
+		BucketHour:  "%Y-%m-%d %H:00:00",
+		BucketDay:   "%Y-%m-%d",
+		BucketWeek:  "%Y-%m-%d",
+		BucketMonth: "%Y-%m-01",
+		BucketYear:  "%Y-01-01",
+	}
+)
+
+var unsupportedJoins = map[Dialect][]Join{
+	DialectMySQL:  {JoinFull},
+	DialectSQLite: {JoinLateral, JoinCrossLateral},
+	DialectMSSQL:  {JoinLateral, JoinCrossLateral},
+}
+
+var (
+	windowFunctionsMutex       sync.RWMutex
+	unsupportedWindowFunctions = map[Dialect]bool{}
+)
+
+// DisableWindowFunctions marks the dialect as not supporting window functions.
+// Should be called on start for databases without them, for example MySQL before 8.0.
+// Pagination of included records is not available for such dialects, see IncludeParams
+func DisableWindowFunctions(dialect Dialect) {
+	windowFunctionsMutex.Lock()
+	defer windowFunctionsMutex.Unlock()
+	unsupportedWindowFunctions[dialect] = true
+}
+
+// SetDialect sets SQL dialect of the database.
+// Dialect is unknown by default and all features are allowed
+func (params *ListParams) SetDialect(dialect Dialect) {
+	params.dialect = dialect
+}
+
+// GetDialect returns SQL dialect set by SetDialect
+func (params *ListParams) GetDialect() Dialect {
+	return params.dialect
+}
+
+// SupportsJoin returns true if the dialect supports join type
+func (d Dialect) SupportsJoin(joinType Join) bool {
+	for _, v := range unsupportedJoins[d] {
+		if v == joinType {
+			return false
+		}
+	}
+	return true
+}
+
+// SupportsWindowFunctions returns true if window functions are not disabled for the dialect
+// by DisableWindowFunctions
+func (d Dialect) SupportsWindowFunctions() bool {
+	windowFunctionsMutex.RLock()
+	defer windowFunctionsMutex.RUnlock()
+	return !unsupportedWindowFunctions[d]
+}
+
+// TimeBucket returns SQL expression of start of the bucket containing time of the column in the location.
+// Columns are expected to store UTC time. PostgreSQL and unknown dialects use DATE_TRUNC,
+// MySQL uses DATE_FORMAT with CONVERT_TZ which needs loaded timezone tables,
+// SQLite uses strftime with current offset of the location. SQLite buckets of records on the other side
+// of daylight saving time transition are shifted by the difference of offsets,
+// so only UTC and locations with fixed offset should be used with SQLite
+func (d Dialect) TimeBucket(column string, bucket TimeBucket, location *time.Location) string {
+	switch d {
+	case DialectMySQL:
+		if location != time.UTC {
+			column = fmt.Sprintf("CONVERT_TZ(%s, '+00:00', '%s')", column, location)
+		}
+		if bucket == BucketWeek {
+			column = fmt.Sprintf("DATE_SUB(%s, INTERVAL WEEKDAY(%s) DAY)", column, column)
+		}
+		return fmt.Sprintf("DATE_FORMAT(%s, '%s')", column, mysqlBucketFormats[bucket])
+	case DialectSQLite:
+		modifiers := ""
+		if _, offset := time.Now().In(location).Zone(); offset != 0 {
+			modifiers += fmt.Sprintf(", '%+d minutes'", offset/60)
+		}
+		if bucket == BucketWeek {
+			modifiers += ", 'weekday 0', '-6 days'"
+		}
+		return fmt.Sprintf("strftime('%s', %s%s)", sqliteBucketFormats[bucket], column, modifiers)
+	}
+	return fmt.Sprintf("DATE_TRUNC('%s', %s AT TIME ZONE '%s')", bucket, column, location)
+}
@@ -0,0 +1,47 @@
+package echo
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/Confialink/wallet-pkg-list_params"
+)
+
+const listParamsKey = "list_params"
+
+// Bind parses list params from the query of the request by the spec and validates them.
+// Returns *echo.HTTPError with JSON:API errors document if params are invalid
+func Bind(c echo.Context, spec *list_params.Spec) (*list_params.ListParams, error) {
+	params := spec.NewListParams(c.Request().URL.RawQuery)
+	if ok, errs := params.ValidateRequest(c.Request()); !ok {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, list_params.NewJSONAPIErrors(errs))
+	}
+	return params, nil
+}
+
+// Middleware binds list params and stores them in the context, see FromContext
+func Middleware(spec *list_params.Spec) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			params, err := Bind(c, spec)
+			if err != nil {
+				return err
+			}
+			c.Set(listParamsKey, params)
+			return next(c)
+		}
+	}
+}
+
+// FromContext returns list params stored by Middleware or nil
+func FromContext(c echo.Context) *list_params.ListParams {
+	params, _ := c.Get(listParamsKey).(*list_params.ListParams)
+	return params
+}
+
+// Respond writes data together with pagination meta and links.
+// total is count of all records matching filters
+func Respond(c echo.Context, params *list_params.ListParams, data interface{}, total int64) error {
+	return c.JSON(http.StatusOK, list_params.NewListResponse(data, params, total, c.Request().URL.Path))
+}
@@ -0,0 +1,12 @@
+module github.com/Confialink/wallet-pkg-list_params/echo
+
+// The root module is not tagged yet. For local development use a workspace:
+// go work init . ./gin ./echo
+// go work edit -replace github.com/Confialink/wallet-pkg-list_params@v0.0.0-00010101000000-000000000000=./
+
+go 1.13
+
+require (
+	github.com/Confialink/wallet-pkg-list_params v0.0.0-00010101000000-000000000000
+	github.com/labstack/echo/v4 v4.1.17
+)
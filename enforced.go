@@ -0,0 +1,81 @@
+package list_params
+
+import (
+	"context"
+	"fmt"
+)
+
+// ConflictPolicy defines how client filters by fields of enforced conditions are handled
+type ConflictPolicy string
+
+const (
+	ConflictReject    = ConflictPolicy("reject")    // Client filter by enforced field makes params invalid
+	ConflictIntersect = ConflictPolicy("intersect") // Client filter is applied together with enforced condition
+)
+
+// EnforcedValues returns values of enforced condition by context of params. Example: id of current user
+type EnforcedValues func(ctx context.Context) ([]string, error)
+
+type enforcedFilter struct {
+	FieldOperatorPair
+	Values EnforcedValues
+}
+
+// Enforce adds mandatory condition. Enforced conditions are always added to GetWhereCondition,
+// they are not allowed to be changed by client and are not encoded into links.
+// Enforced field can be overridden by custom filter as usual filter
+func (params *ListParams) Enforce(field string, values []string, operator ...Operator) {
+	params.EnforceFromContext(field, func(context.Context) ([]string, error) {
+		return values, nil
+	}, operator...)
+}
+
+// EnforceFromContext adds mandatory condition with values taken from context of params, see Enforce and SetContext.
+// If values can not be returned or are empty GetWhereCondition returns condition matching no records
+func (params *ListParams) EnforceFromContext(field string, values EnforcedValues, operator ...Operator) {
+	op := OperatorEq
+	if len(operator) != 0 {
+		op = operator[0]
+	}
+	params.enforcedFilters = append(params.enforcedFilters,
+		enforcedFilter{FieldOperatorPair{Field: field, Operator: op}, values})
+}
+
+// SetConflictPolicy sets handling of client filters by fields of enforced conditions.
+// ConflictReject is used by default
+func (params *ListParams) SetConflictPolicy(policy ConflictPolicy) {
+	params.conflictPolicy = policy
+}
+
+// getEnforcedFilters returns enforced conditions with values taken from context
+func (params *ListParams) getEnforcedFilters() ([]FilterListParameter, error) {
+	result := make([]FilterListParameter, len(params.enforcedFilters))
+	for i, enforced := range params.enforcedFilters {
+		values, err := enforced.Values(params.Context())
+		if err != nil {
+			return nil, fmt.Errorf("enforced condition %s: %w", enforced.Field, err)
+		}
+		result[i] = FilterListParameter{enforced.FieldOperatorPair, values}
+	}
+	return result, nil
+}
+
+// isEnforcedField returns true if the field has enforced condition with any operator
+func (params *ListParams) isEnforcedField(field string) bool {
+	for _, enforced := range params.enforcedFilters {
+		if enforced.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// isEnforcedConflict returns true if client filter changes enforced condition and it is rejected by policy
+func (params *ListParams) isEnforcedConflict(filter *FilterListParameter) bool {
+	return params.conflictPolicy != ConflictIntersect && params.isEnforcedField(filter.Field)
+}
+
+func (params *ListParams) addEnforcedError(filter *FilterListParameter) {
+	params.addError(newParamError(CodeFilterEnforced, filterParameter(filter.Field, filter.Operator),
+		filter.Field, filter.Operator, "", nil))
+}
@@ -0,0 +1,98 @@
+package list_params
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestEnforcedConditionIsAddedToClientFilters(t *testing.T) {
+	params := NewListParamsFromQuery("filter[amount]=10", card{})
+	params.AllowFilters([]string{"amount"})
+	params.Enforce("user_id", []string{"3"})
+
+	where, args := params.GetWhereCondition()
+	expected := "((cards.amount = ?)) AND ((cards.user_id = ?))"
+	if where != expected {
+		t.Errorf("expected %s, got %s", expected, where)
+	}
+	if fmt.Sprint(args) != "[[10] [3]]" {
+		t.Errorf("unexpected arguments %#v", args)
+	}
+}
+
+func TestEnforcedConditionWithoutValuesMatchesNoRecords(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		enforce func(params *ListParams)
+	}{
+		{"nil values", "", func(params *ListParams) { params.Enforce("user_id", nil) }},
+		{"nil values with client filter", "filter[amount]=10", func(params *ListParams) {
+			params.Enforce("user_id", nil)
+		}},
+		{"empty values from context", "filter[amount]=10", func(params *ListParams) {
+			params.EnforceFromContext("user_id", func(context.Context) ([]string, error) {
+				return []string{}, nil
+			})
+		}},
+		{"custom filter rendering nothing", "", func(params *ListParams) {
+			params.AddCustomFilter("user_id", func([]string, *ListParams) (string, interface{}) {
+				return "", nil
+			})
+			params.Enforce("user_id", []string{"3"})
+		}},
+		{"second enforced condition without values", "", func(params *ListParams) {
+			params.Enforce("status", []string{"active"})
+			params.Enforce("user_id", []string{})
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			params := NewListParamsFromQuery(test.query, card{})
+			params.AllowFilters([]string{"amount"})
+			test.enforce(params)
+
+			where, args, err := params.GetWhereConditionWithError()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if where != noRecordsCondition || len(args) != 0 {
+				t.Errorf("expected %s without arguments, got %s %v", noRecordsCondition, where, args)
+			}
+		})
+	}
+}
+
+func TestEnforcedConditionError(t *testing.T) {
+	params := NewListParamsFromQuery("", card{})
+	params.EnforceFromContext("user_id", func(context.Context) ([]string, error) {
+		return nil, errors.New("no user")
+	})
+
+	if _, _, err := params.GetWhereConditionWithError(); err == nil {
+		t.Error("expected error of enforced condition")
+	}
+	if where, _ := params.GetWhereCondition(); where != noRecordsCondition {
+		t.Errorf("expected %s, got %s", noRecordsCondition, where)
+	}
+}
+
+func TestEnforcedFieldCanNotBeFilteredByClient(t *testing.T) {
+	params := NewListParamsFromQuery("filter[user_id]=4", card{})
+	params.AllowFilters([]string{"user_id"})
+	params.Enforce("user_id", []string{"3"})
+
+	if ok, errs := params.Validate(); ok || AsParamError(errs[0]).Code != CodeFilterEnforced {
+		t.Errorf("expected %s error, got %v", CodeFilterEnforced, errs)
+	}
+
+	params = NewListParamsFromQuery("filter[user_id]=4", card{})
+	params.AllowFilters([]string{"user_id"})
+	params.Enforce("user_id", []string{"3"})
+	params.SetConflictPolicy(ConflictIntersect)
+	if ok, errs := params.Validate(); !ok {
+		t.Errorf("expected valid params, got %v", errs)
+	}
+}
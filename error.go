@@ -1,5 +1,58 @@
 package list_params
 
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode is stable machine readable code of validation error
+type ErrorCode string
+
+const (
+	CodeInvalidQuery            = ErrorCode("invalid_query")
+	CodeFilterNotAllowed        = ErrorCode("filter_not_allowed")
+	CodeSortNotAllowed          = ErrorCode("sort_not_allowed")
+	CodeIncludeNotAllowed       = ErrorCode("include_not_allowed")
+	CodePaginationNotAllowed    = ErrorCode("pagination_not_allowed")
+	CodeFilterValueInvalid      = ErrorCode("filter_value_invalid")
+	CodeFilterValueNotInEnum    = ErrorCode("filter_value_not_in_enum")
+	CodeFilterValuePattern      = ErrorCode("filter_value_pattern_mismatch")
+	CodeFilterValueTooSmall     = ErrorCode("filter_value_too_small")
+	CodeFilterValueTooLarge     = ErrorCode("filter_value_too_large")
+	CodeFilterValueTooLong      = ErrorCode("filter_value_too_long")
+	CodeFilterTooManyValues     = ErrorCode("filter_too_many_values")
+	CodeQuantifierInvalid       = ErrorCode("quantifier_invalid")
+	CodeIncludeParentNotAllowed = ErrorCode("include_parent_not_allowed")
+	CodeIncludeTooDeep          = ErrorCode("include_too_deep")
+	CodeIncludeRelationNotFound = ErrorCode("include_relation_not_found")
+	CodeSortRejected            = ErrorCode("sort_rejected")
+	CodeFilterEnforced          = ErrorCode("filter_enforced")
+	CodeFilterForbidden         = ErrorCode("filter_forbidden")
+	CodeSortForbidden           = ErrorCode("sort_forbidden")
+	CodeIncludeForbidden        = ErrorCode("include_forbidden")
+	CodeSortMasked              = ErrorCode("sort_masked")
+	CodeFilterMasked            = ErrorCode("filter_masked")
+	CodeFieldNotAllowed         = ErrorCode("field_not_allowed")
+	CodeGroupNotAllowed         = ErrorCode("group_not_allowed")
+	CodeAggregateNotAllowed     = ErrorCode("aggregate_not_allowed")
+	CodeAggregateInvalid        = ErrorCode("aggregate_invalid")
+	CodeHavingNotAllowed        = ErrorCode("having_not_allowed")
+	CodeGroupBucketInvalid      = ErrorCode("group_bucket_invalid")
+	CodeTimezoneInvalid         = ErrorCode("timezone_invalid")
+	CodeCountNotAvailable       = ErrorCode("count_not_available")
+)
+
+var ruleCodes = map[string]ErrorCode{
+	RuleType:      CodeFilterValueInvalid,
+	RuleEnum:      CodeFilterValueNotInEnum,
+	RulePattern:   CodeFilterValuePattern,
+	RuleMin:       CodeFilterValueTooSmall,
+	RuleMax:       CodeFilterValueTooLarge,
+	RuleMaxLength: CodeFilterValueTooLong,
+	RuleMaxValues: CodeFilterTooManyValues,
+	RuleCustom:    CodeFilterValueInvalid,
+}
+
 func NewErrorString(text string) error {
 	return &ErrorString{text}
 }
@@ -10,4 +63,38 @@ type ErrorString struct {
 
 func (e *ErrorString) Error() string {
 	return e.S
-}
\ No newline at end of file
+}
+
+// ParamError describes invalid query parameter.
+// All errors returned by ListParams.Validate can be unwrapped to it by errors.As
+type ParamError struct {
+	Code      ErrorCode `json:"code"`
+	Parameter string    `json:"parameter,omitempty"` // Query parameter. Example: filter[amount:gt], sort, include
+	Field     string    `json:"field,omitempty"`
+	Operator  Operator  `json:"operator,omitempty"`
+	Value     string    `json:"value,omitempty"`
+	Detail    string    `json:"detail"`
+
+	Args map[string]string `json:"-"` // Arguments of message template. Example: allowed values of enum
+}
+
+func (e *ParamError) Error() string {
+	return e.Detail
+}
+
+// AsParamError returns ParamError found in the error chain.
+// Errors of other types are converted to ParamError with CodeInvalidQuery
+func AsParamError(err error) *ParamError {
+	var paramErr *ParamError
+	if errors.As(err, &paramErr) {
+		return paramErr
+	}
+	return &ParamError{Code: CodeInvalidQuery, Detail: err.Error()}
+}
+
+func filterParameter(field string, operator Operator) string {
+	if operator == OperatorEq {
+		return fmt.Sprintf("filter[%s]", field)
+	}
+	return fmt.Sprintf("filter[%s]", filterWithOperator(field, operator))
+}
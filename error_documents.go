@@ -0,0 +1,133 @@
+package list_params
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+const (
+	JSONAPIContentType = "application/vnd.api+json"
+	ProblemContentType = "application/problem+json"
+)
+
+const defaultProblemType = "about:blank"
+const problemTitle = "Invalid list parameters"
+
+var errorTitles = map[ErrorCode]string{
+	CodeInvalidQuery:            "Invalid query",
+	CodeFilterNotAllowed:        "Filter is not allowed",
+	CodeSortNotAllowed:          "Sorting is not allowed",
+	CodeIncludeNotAllowed:       "Including is not allowed",
+	CodePaginationNotAllowed:    "Pagination is not allowed",
+	CodeFilterValueInvalid:      "Invalid filter value",
+	CodeFilterValueNotInEnum:    "Invalid filter value",
+	CodeFilterValuePattern:      "Invalid filter value",
+	CodeFilterValueTooSmall:     "Invalid filter value",
+	CodeFilterValueTooLarge:     "Invalid filter value",
+	CodeFilterValueTooLong:      "Invalid filter value",
+	CodeFilterTooManyValues:     "Too many filter values",
+	CodeQuantifierInvalid:       "Invalid quantifier",
+	CodeIncludeParentNotAllowed: "Including is not allowed",
+	CodeIncludeTooDeep:          "Include is too deep",
+	CodeIncludeRelationNotFound: "Unknown relation",
+	CodeSortRejected:            "Invalid sorting",
+	CodeFilterEnforced:          "Filter is not allowed",
+	CodeFilterForbidden:         "Filter is forbidden",
+	CodeSortForbidden:           "Sorting is forbidden",
+	CodeIncludeForbidden:        "Including is forbidden",
+	CodeSortMasked:              "Sorting is not allowed",
+	CodeFilterMasked:            "Filter is not allowed",
+	CodeFieldNotAllowed:         "Field is not allowed",
+	CodeGroupNotAllowed:         "Grouping is not allowed",
+	CodeAggregateNotAllowed:     "Aggregate is not allowed",
+	CodeAggregateInvalid:        "Invalid aggregate",
+	CodeHavingNotAllowed:        "Having filter is not allowed",
+	CodeGroupBucketInvalid:      "Invalid time bucket",
+	CodeTimezoneInvalid:         "Invalid timezone",
+	CodeCountNotAvailable:       "Count is not available",
+}
+
+// JSONAPIErrors is JSON:API document with top level errors member
+type JSONAPIErrors struct {
+	Errors []JSONAPIError `json:"errors"`
+}
+
+// JSONAPIError is JSON:API error object
+type JSONAPIError struct {
+	Status string              `json:"status"`
+	Code   ErrorCode           `json:"code"`
+	Title  string              `json:"title"`
+	Detail string              `json:"detail"`
+	Source *JSONAPIErrorSource `json:"source,omitempty"`
+}
+
+// JSONAPIErrorSource points to query parameter caused the error
+type JSONAPIErrorSource struct {
+	Parameter string `json:"parameter"`
+}
+
+// Problem is RFC 7807 problem details document.
+// Errors is extension member with all validation errors
+type Problem struct {
+	Type     string        `json:"type"`
+	Title    string        `json:"title"`
+	Status   int           `json:"status"`
+	Detail   string        `json:"detail,omitempty"`
+	Instance string        `json:"instance,omitempty"`
+	Errors   []*ParamError `json:"errors"`
+}
+
+// NewJSONAPIErrors creates JSON:API errors document from errors returned by Validate
+func NewJSONAPIErrors(errs []error) *JSONAPIErrors {
+	document := &JSONAPIErrors{Errors: make([]JSONAPIError, len(errs))}
+	for i, err := range errs {
+		paramErr := AsParamError(err)
+		document.Errors[i] = JSONAPIError{
+			Status: strconv.Itoa(http.StatusBadRequest),
+			Code:   paramErr.Code,
+			Title:  errorTitle(paramErr.Code),
+			Detail: paramErr.Detail,
+		}
+		if paramErr.Parameter != "" {
+			document.Errors[i].Source = &JSONAPIErrorSource{paramErr.Parameter}
+		}
+	}
+	return document
+}
+
+// NewProblem creates RFC 7807 problem details from errors returned by Validate
+func NewProblem(errs []error) *Problem {
+	problem := &Problem{
+		Type:   defaultProblemType,
+		Title:  problemTitle,
+		Status: http.StatusBadRequest,
+		Errors: make([]*ParamError, len(errs)),
+	}
+	for i, err := range errs {
+		problem.Errors[i] = AsParamError(err)
+	}
+	if len(errs) == 1 {
+		problem.Detail = problem.Errors[0].Detail
+	}
+	return problem
+}
+
+// RenderJSONAPIErrors returns JSON:API errors document as JSON.
+// Should be sent with JSONAPIContentType
+func RenderJSONAPIErrors(errs []error) ([]byte, error) {
+	return json.Marshal(NewJSONAPIErrors(errs))
+}
+
+// RenderProblem returns RFC 7807 problem details as JSON.
+// Should be sent with ProblemContentType
+func RenderProblem(errs []error) ([]byte, error) {
+	return json.Marshal(NewProblem(errs))
+}
+
+func errorTitle(code ErrorCode) string {
+	if title, ok := errorTitles[code]; ok {
+		return title
+	}
+	return errorTitles[CodeInvalidQuery]
+}
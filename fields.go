@@ -1,7 +1,6 @@
 package list_params
 
 import (
-	"reflect"
 	"strings"
 )
 
@@ -14,64 +13,175 @@ type Fields struct {
 	NestedFields []Fields
 }
 
+// NewFields returns empty root of fields tree
+func NewFields() Fields {
+	return newFields("")
+}
+
+// InterfaceArrayToFields returns fields tree from list in format of AllowSelectFields
 func InterfaceArrayToFields(values []interface{}) Fields {
 	return interfaceArrayToFieldsRecursively("", values)
 }
 
+// StringsArrayToFields returns fields tree from dotted paths. Example: id, author.name, author.email
 func StringsArrayToFields(values []string) Fields {
-	fields := Fields{PropName: "", List: make([]string, 0), NestedFields: make([]Fields, 0)}
+	fields := NewFields()
 	for _, value := range values {
-		addFieldToFields(&fields, value)
+		fields.Add(value)
 	}
 	return fields
 }
 
+// Add adds field by dotted path. Fields already in the tree are not duplicated
+func (f *Fields) Add(path string) {
+	addFieldToFields(f, path)
+}
+
+// ToInterfaceArray returns fields in format of AllowSelectFields.
+// Every nested model is returned as separate map
 func (f *Fields) ToInterfaceArray() []interface{} {
-	result := make([]interface{}, 0)
+	result := make([]interface{}, 0, len(f.List)+len(f.NestedFields))
 
 	for _, v := range f.List {
 		result = append(result, v)
 	}
-
-	nestedMap := make(map[string][]interface{})
 	for _, v := range f.NestedFields {
-		nestedMap[v.PropName] = v.ToInterfaceArray()
+		result = append(result, map[string][]interface{}{v.PropName: v.ToInterfaceArray()})
+	}
+
+	return result
+}
+
+// Paths returns dotted paths of all fields. Nested models without fields are skipped
+func (f *Fields) Paths() []string {
+	result := make([]string, 0, len(f.List))
+	for _, v := range f.List {
+		result = append(result, v)
+	}
+	for _, nested := range f.NestedFields {
+		for _, path := range nested.Paths() {
+			result = append(result, nested.PropName+defaultFieldsDelimiter+path)
+		}
 	}
-	result = append(result, nestedMap)
+	return result
+}
 
+// Contains returns true if the tree has field or nested model by dotted path
+func (f *Fields) Contains(path string) bool {
+	splitField := strings.SplitN(path, defaultFieldsDelimiter, 2)
+	if len(splitField) == 1 {
+		return f.hasField(path) || f.getNestedFields(path) != nil
+	}
+	nested := f.getNestedFields(splitField[0])
+	return nested != nil && nested.Contains(splitField[1])
+}
+
+// Merge returns union of fields trees
+func (f *Fields) Merge(other Fields) Fields {
+	result := f.copy()
+	for _, v := range other.List {
+		if !result.hasField(v) {
+			result.List = append(result.List, v)
+		}
+	}
+	for _, otherNested := range other.NestedFields {
+		if nested := result.getNestedFields(otherNested.PropName); nested != nil {
+			*nested = nested.Merge(otherNested)
+		} else {
+			result.NestedFields = append(result.NestedFields, otherNested.copy())
+		}
+	}
 	return result
 }
 
+// Intersect returns fields presented in both trees in order of the tree.
+// Nested model is kept if it is presented in both trees even without common fields
+func (f *Fields) Intersect(other Fields) Fields {
+	result := newFields(f.PropName)
+	for _, v := range f.List {
+		if other.hasField(v) {
+			result.List = append(result.List, v)
+		}
+	}
+	for _, nested := range f.NestedFields {
+		if otherNested := other.getNestedFields(nested.PropName); otherNested != nil {
+			result.NestedFields = append(result.NestedFields, nested.Intersect(*otherNested))
+		}
+	}
+	return result
+}
+
+func (f *Fields) hasField(name string) bool {
+	for _, v := range f.List {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Fields) getNestedFields(propName string) *Fields {
+	for i := range f.NestedFields {
+		if f.NestedFields[i].PropName == propName {
+			return &f.NestedFields[i]
+		}
+	}
+	return nil
+}
+
+func (f *Fields) copy() Fields {
+	result := newFields(f.PropName)
+	result.List = append(result.List, f.List...)
+	for _, nested := range f.NestedFields {
+		result.NestedFields = append(result.NestedFields, nested.copy())
+	}
+	return result
+}
+
+func newFields(propName string) Fields {
+	return Fields{PropName: propName, List: make([]string, 0), NestedFields: make([]Fields, 0)}
+}
+
 func addFieldToFields(model *Fields, field string) {
 	splitedValue := strings.Split(field, defaultFieldsDelimiter)
 	if len(splitedValue) == 1 {
-		model.List = append(model.List, field)
+		if !model.hasField(field) {
+			model.List = append(model.List, field)
+		}
 	} else {
 		addNestedFieldToFields(model, splitedValue)
 	}
 }
 
 func addNestedFieldToFields(model *Fields, splitedField []string) {
-	for _, v := range model.NestedFields {
-		if v.PropName == splitedField[0] {
-			addFieldToFields(&v, strings.Join(splitedField[1:], defaultFieldsDelimiter))
-			return
-		}
+	if nested := model.getNestedFields(splitedField[0]); nested != nil {
+		addFieldToFields(nested, strings.Join(splitedField[1:], defaultFieldsDelimiter))
+		return
 	}
-	newFields := Fields{PropName: splitedField[0], List: make([]string, 0), NestedFields: make([]Fields, 0)}
+	newFields := newFields(splitedField[0])
 	addFieldToFields(&newFields, strings.Join(splitedField[1:], defaultFieldsDelimiter))
 	model.NestedFields = append(model.NestedFields, newFields)
 }
 
+// interfaceArrayToFieldsRecursively returns fields tree of the level.
+// Elements which are neither strings nor maps of nested fields, such as nil, are skipped
 func interfaceArrayToFieldsRecursively(propName string, values []interface{}) Fields {
-	newFields := Fields{PropName: propName, List: make([]string, 0), NestedFields: make([]Fields, 0)}
+	newFields := newFields(propName)
 
 	for _, field := range values {
-		if reflect.TypeOf(field).Kind() == reflect.String {
-			newFields.List = append(newFields.List, field.(string))
-		} else {
-			for k, mapFields := range field.(map[string][]interface{}) {
-				newFields.NestedFields = append(newFields.NestedFields, interfaceArrayToFieldsRecursively(k, mapFields))
+		switch value := field.(type) {
+		case string:
+			if !newFields.hasField(value) {
+				newFields.List = append(newFields.List, value)
+			}
+		case map[string][]interface{}:
+			for k, mapFields := range value {
+				nested := interfaceArrayToFieldsRecursively(k, mapFields)
+				if existing := newFields.getNestedFields(k); existing != nil {
+					*existing = existing.Merge(nested)
+				} else {
+					newFields.NestedFields = append(newFields.NestedFields, nested)
+				}
 			}
 		}
 	}
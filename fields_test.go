@@ -0,0 +1,116 @@
+package list_params
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+var fieldNames = []string{"id", "name", "amount", "status", "createdAt"}
+var nestedNames = []string{"Account", "User", "Cards"}
+
+// fieldsTree is random fields tree without duplicated fields and nested models on every level
+type fieldsTree struct {
+	Fields Fields
+}
+
+func (fieldsTree) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(fieldsTree{Fields: generateFields(r, "", 3)})
+}
+
+func generateFields(r *rand.Rand, propName string, depth int) Fields {
+	fields := newFields(propName)
+	for _, name := range fieldNames {
+		if r.Intn(2) == 0 {
+			fields.List = append(fields.List, name)
+		}
+	}
+	if depth == 0 {
+		return fields
+	}
+	for _, name := range nestedNames {
+		if r.Intn(3) == 0 {
+			fields.NestedFields = append(fields.NestedFields, generateFields(r, name, depth-1))
+		}
+	}
+	return fields
+}
+
+func TestFieldsInterfaceArrayRoundTrip(t *testing.T) {
+	property := func(tree fieldsTree) bool {
+		return reflect.DeepEqual(InterfaceArrayToFields(tree.Fields.ToInterfaceArray()), tree.Fields)
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFieldsPathsRoundTrip(t *testing.T) {
+	property := func(tree fieldsTree) bool {
+		paths := tree.Fields.Paths()
+		restored := StringsArrayToFields(paths)
+		return reflect.DeepEqual(restored.Paths(), paths)
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFieldsMerge(t *testing.T) {
+	property := func(a, b fieldsTree) bool {
+		merged := a.Fields.Merge(b.Fields)
+		for _, path := range append(a.Fields.Paths(), b.Fields.Paths()...) {
+			if !merged.Contains(path) {
+				return false
+			}
+		}
+		for _, path := range merged.Paths() {
+			if !a.Fields.Contains(path) && !b.Fields.Contains(path) {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFieldsIntersect(t *testing.T) {
+	property := func(a, b fieldsTree) bool {
+		intersected := a.Fields.Intersect(b.Fields)
+		for _, path := range intersected.Paths() {
+			if !a.Fields.Contains(path) || !b.Fields.Contains(path) {
+				return false
+			}
+		}
+		for _, path := range a.Fields.Paths() {
+			if b.Fields.Contains(path) && !intersected.Contains(path) {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFieldsMergeAndIntersectWithItself(t *testing.T) {
+	property := func(tree fieldsTree) bool {
+		return reflect.DeepEqual(tree.Fields.Merge(tree.Fields), tree.Fields) &&
+			reflect.DeepEqual(tree.Fields.Intersect(tree.Fields), tree.Fields)
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInterfaceArrayToFieldsSkipsNil(t *testing.T) {
+	fields := InterfaceArrayToFields([]interface{}{"id", nil, map[string][]interface{}{"Account": {nil, "name"}}})
+	expected := []string{"id", "Account.name"}
+	if paths := fields.Paths(); !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected %v, got %v", expected, paths)
+	}
+}
@@ -0,0 +1,52 @@
+package gin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Confialink/wallet-pkg-list_params"
+)
+
+const listParamsKey = "list_params"
+
+// Bind parses list params from the query of the request by the spec and validates them.
+// Aborts the request with JSON:API errors document if params are invalid
+func Bind(c *gin.Context, spec *list_params.Spec) (*list_params.ListParams, bool) {
+	params := spec.NewListParams(c.Request.URL.RawQuery)
+	if ok, errs := params.ValidateRequest(c.Request); !ok {
+		AbortWithErrors(c, errs)
+		return nil, false
+	}
+	return params, true
+}
+
+// Middleware binds list params and stores them in the context, see FromContext
+func Middleware(spec *list_params.Spec) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if params, ok := Bind(c, spec); ok {
+			c.Set(listParamsKey, params)
+			c.Next()
+		}
+	}
+}
+
+// FromContext returns list params stored by Middleware or nil
+func FromContext(c *gin.Context) *list_params.ListParams {
+	if value, ok := c.Get(listParamsKey); ok {
+		return value.(*list_params.ListParams)
+	}
+	return nil
+}
+
+// AbortWithErrors aborts the request with JSON:API errors document
+func AbortWithErrors(c *gin.Context, errs []error) {
+	c.Header("Content-Type", list_params.JSONAPIContentType)
+	c.AbortWithStatusJSON(http.StatusBadRequest, list_params.NewJSONAPIErrors(errs))
+}
+
+// Respond writes data together with pagination meta and links.
+// total is count of all records matching filters
+func Respond(c *gin.Context, params *list_params.ListParams, data interface{}, total int64) {
+	c.JSON(http.StatusOK, list_params.NewListResponse(data, params, total, c.Request.URL.Path))
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Confialink/wallet-pkg-list_params"
+)
+
+type contextKey struct{}
+
+var listParamsKey = contextKey{}
+
+// ErrorWriter writes response for invalid list params
+type ErrorWriter func(w http.ResponseWriter, r *http.Request, errs []error)
+
+// Middleware parses list params from r.URL.RawQuery by the spec and validates them.
+// Valid params are stored in the request context, see FromContext.
+// Invalid params are responded by errorWriter, WriteJSONAPIErrors is used by default
+func Middleware(spec *list_params.Spec, errorWriter ...ErrorWriter) func(http.Handler) http.Handler {
+	writeErrors := WriteJSONAPIErrors
+	if len(errorWriter) != 0 {
+		writeErrors = errorWriter[0]
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			params := spec.NewListParams(r.URL.RawQuery)
+			if ok, errs := params.ValidateRequest(r); !ok {
+				writeErrors(w, r, errs)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), params)))
+		})
+	}
+}
+
+// HandlerFunc wraps handler receiving valid list params
+func HandlerFunc(spec *list_params.Spec,
+	handler func(w http.ResponseWriter, r *http.Request, params *list_params.ListParams),
+	errorWriter ...ErrorWriter) http.Handler {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(w, r, FromRequest(r))
+	})
+	return Middleware(spec, errorWriter...)(next)
+}
+
+// NewContext returns new context with list params
+func NewContext(ctx context.Context, params *list_params.ListParams) context.Context {
+	return context.WithValue(ctx, listParamsKey, params)
+}
+
+// FromContext returns list params stored by Middleware
+func FromContext(ctx context.Context) (*list_params.ListParams, bool) {
+	params, ok := ctx.Value(listParamsKey).(*list_params.ListParams)
+	return params, ok
+}
+
+// FromRequest returns list params stored by Middleware or nil
+func FromRequest(r *http.Request) *list_params.ListParams {
+	params, _ := FromContext(r.Context())
+	return params
+}
+
+// WriteJSONAPIErrors responds with JSON:API errors document
+func WriteJSONAPIErrors(w http.ResponseWriter, _ *http.Request, errs []error) {
+	body, err := list_params.RenderJSONAPIErrors(errs)
+	writeBody(w, list_params.JSONAPIContentType, body, err)
+}
+
+// WriteProblem responds with RFC 7807 problem details
+func WriteProblem(w http.ResponseWriter, _ *http.Request, errs []error) {
+	body, err := list_params.RenderProblem(errs)
+	writeBody(w, list_params.ProblemContentType, body, err)
+}
+
+func writeBody(w http.ResponseWriter, contentType string, body []byte, err error) {
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write(body)
+}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Confialink/wallet-pkg-list_params"
+)
+
+type transaction struct {
+	ID     uint
+	Amount int64
+	Status string
+}
+
+var spec = &list_params.Spec{
+	Object:     transaction{},
+	Filters:    []string{"status"},
+	Sortings:   []string{"amount", "status"},
+	Pagination: true,
+}
+
+func serve(query string, errorWriter ...ErrorWriter) (*httptest.ResponseRecorder, *list_params.ListParams) {
+	var params *list_params.ListParams
+	handler := HandlerFunc(spec, func(w http.ResponseWriter, r *http.Request, p *list_params.ListParams) {
+		params = p
+		w.WriteHeader(http.StatusOK)
+	}, errorWriter...)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/transactions?"+query, nil))
+	return recorder, params
+}
+
+func TestMiddlewareStoresValidParams(t *testing.T) {
+	recorder, params := serve("filter[status]=completed&sort=-amount&page[size]=5")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", recorder.Code, recorder.Body)
+	}
+	if params == nil {
+		t.Fatal("expected list params in request context")
+	}
+	if len(params.Sortings) != 1 || params.Sortings[0].Field != "amount" ||
+		params.Sortings[0].Direction != list_params.DescDirection {
+		t.Errorf("unexpected sortings %+v", params.Sortings)
+	}
+	if params.GetLimit() != 5 {
+		t.Errorf("expected limit 5, got %d", params.GetLimit())
+	}
+}
+
+func TestMiddlewareSkipsEmptySortFields(t *testing.T) {
+	for _, query := range []string{"sort=", "sort=amount,,-status", "sort=,"} {
+		recorder, params := serve(query)
+		if recorder.Code != http.StatusOK {
+			t.Errorf("%s: expected status 200, got %d: %s", query, recorder.Code, recorder.Body)
+			continue
+		}
+		for _, sorting := range params.Sortings {
+			if sorting.Field == "" {
+				t.Errorf("%s: unexpected empty sorting field in %+v", query, params.Sortings)
+			}
+		}
+	}
+}
+
+func TestMiddlewareRespondsWithErrors(t *testing.T) {
+	recorder, params := serve("filter[amount]=10")
+	if params != nil {
+		t.Error("handler should not be called for invalid params")
+	}
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", recorder.Code)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); contentType != list_params.JSONAPIContentType {
+		t.Errorf("expected content type %s, got %s", list_params.JSONAPIContentType, contentType)
+	}
+	if !strings.Contains(recorder.Body.String(), string(list_params.CodeFilterNotAllowed)) {
+		t.Errorf("expected %s error, got %s", list_params.CodeFilterNotAllowed, recorder.Body)
+	}
+}
+
+func TestMiddlewareUsesErrorWriter(t *testing.T) {
+	recorder, _ := serve("sort=id", WriteProblem)
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", recorder.Code)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); contentType != list_params.ProblemContentType {
+		t.Errorf("expected content type %s, got %s", list_params.ProblemContentType, contentType)
+	}
+}
@@ -0,0 +1,224 @@
+package list_params
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const rowNumberAlias = "list_params_row"
+
+// Preload describes eager loading of included relation with conditions of its scope
+type Preload struct {
+	Name  string // Name of relation in format of GetPreloads. Example: Author.Likes
+	Where string
+	Args  []interface{}
+	Order string
+}
+
+// IncludeParams returns list params of included relation.
+// Allowed filters, sortings and pagination of returned params are applied to included records:
+// include=transactions&filter[transactions.status]=completed&sort[transactions]=-created_at&page[transactions][size]=5
+// Filter by relation field is applied to included records only if it is not allowed for the list itself.
+// If the relation does not exist, returned params are not applied and Validate rejects including of the relation
+func (params *ListParams) IncludeParams(include string) *ListParams {
+	if scope, ok := params.includeScopes[include]; ok {
+		return scope
+	}
+
+	scope := NewListParams()
+	scope.ObjectType, _ = params.getIncludeType(include)
+	scope.dialect = params.dialect
+	prefix := include + sqlTableFieldDelimiter
+	for _, filter := range params.Filters {
+		if strings.HasPrefix(filter.Field, prefix) {
+			pair := FieldOperatorPair{Field: strings.TrimPrefix(filter.Field, prefix), Operator: filter.Operator}
+			scope.Filters = append(scope.Filters, FilterListParameter{pair, filter.Values})
+		}
+	}
+	scope.setSortingParams(url.Values{"sort": params.rawQuery[fmt.Sprintf("sort[%s]", include)]})
+	scope.Pagination = PaginationListParameter{PageNumber: DefaultPageNumber}
+	if number, err := strconv.ParseUint(params.rawQuery.Get(fmt.Sprintf("page[%s][number]", include)), 10, 32); err == nil {
+		scope.Pagination.PageNumber = uint32(number)
+	}
+	if size, err := strconv.ParseUint(params.rawQuery.Get(fmt.Sprintf("page[%s][size]", include)), 10, 32); err == nil {
+		scope.Pagination.PageSize = uint32(size)
+	}
+
+	params.includeScopes[include] = scope
+	return scope
+}
+
+// GetPreloadsWithConditions returns preloads as GetPreloads does
+// together with conditions of included relations scopes, see IncludeParams.
+// Pagination of included records is skipped if the dialect does not support window functions.
+// Use GetPreloadsWithConditionsWithError to get the error
+func (params *ListParams) GetPreloadsWithConditions() []Preload {
+	preloads, _ := params.getPreloadsWithConditions()
+	return preloads
+}
+
+// GetPreloadsWithConditionsWithError returns preloads as GetPreloadsWithConditions does
+// or error if pagination of included records is not supported by the dialect
+func (params *ListParams) GetPreloadsWithConditionsWithError() ([]Preload, error) {
+	preloads, err := params.getPreloadsWithConditions()
+	if err != nil {
+		return nil, err
+	}
+	return preloads, nil
+}
+
+// getPreloadsWithConditions returns preloads and the first error of pagination of included records
+func (params *ListParams) getPreloadsWithConditions() ([]Preload, error) {
+	var err error
+	includes := params.Includes.getNotCustomIncludes()
+	preloads := make([]Preload, len(includes))
+	for i, include := range includes {
+		preloads[i] = Preload{Name: params.Includes.transformPreloading(include)}
+		scope, ok := params.includeScopes[include]
+		if !ok || scope.ObjectType == nil {
+			continue
+		}
+		preloads[i].Where, preloads[i].Args = params.getScopeWhereCondition(include, scope)
+		preloads[i].Order = scope.GetOrderByString()
+		if scope.Pagination.PageSize == 0 {
+			continue
+		}
+		if !params.dialect.SupportsWindowFunctions() {
+			if err == nil {
+				err = newParamError(CodePaginationNotAllowed, fmt.Sprintf("page[%s]", include), include, "", "", nil)
+			}
+			continue
+		}
+		preloads[i].Where, preloads[i].Args = params.limitIncludeScope(include, scope, preloads[i].Where, preloads[i].Args)
+	}
+	return preloads, err
+}
+
+// isIncludeScopeFilter returns true if the filter is allowed only for included relation
+func (params *ListParams) isIncludeScopeFilter(filter *FilterListParameter) bool {
+	if params.isAllowedFilter(filter.Field, filter.Operator) {
+		return false
+	}
+	for include, scope := range params.includeScopes {
+		prefix := include + sqlTableFieldDelimiter
+		if strings.HasPrefix(filter.Field, prefix) && params.Includes.isIncluded(include) &&
+			scope.isAllowedFilter(strings.TrimPrefix(filter.Field, prefix), filter.Operator) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateIncludeScopes validates filters, sortings and pagination of included relations
+func (params *ListParams) validateIncludeScopes() {
+	for include, scope := range params.includeScopes {
+		if scope.ObjectType == nil {
+			if params.Includes.isIncluded(include) && !params.verifyIncludes {
+				params.addError(newParamError(CodeIncludeRelationNotFound, "include", include, "", "", nil))
+			}
+			continue
+		}
+		for _, filter := range scope.Filters {
+			if !scope.isAllowedFilter(filter.Field, filter.Operator) {
+				continue
+			}
+			if err := scope.validateFilterValues(&filter); err != nil {
+				field := include + sqlTableFieldDelimiter + filter.Field
+				params.addError(newParamError(err.Code, filterParameter(field, filter.Operator),
+					field, filter.Operator, err.Value, err.Args))
+			}
+		}
+		for _, sorting := range scope.Sortings {
+			if !scope.isAllowedSorting(sorting.Field) {
+				params.addError(newParamError(CodeSortNotAllowed, fmt.Sprintf("sort[%s]", include),
+					include+sqlTableFieldDelimiter+sorting.Field, "", "", nil))
+			}
+		}
+		pagination := scope.Pagination
+		if !scope.allowedListParams.Pagination && (pagination.PageNumber != DefaultPageNumber || pagination.PageSize != 0) {
+			params.addError(newParamError(CodePaginationNotAllowed, fmt.Sprintf("page[%s]", include),
+				include, "", "", nil))
+		}
+	}
+}
+
+// getScopeWhereCondition returns where condition of filters allowed for the included relation
+// and not allowed for the list itself. Returns condition matching no records if a context filter fails
+func (params *ListParams) getScopeWhereCondition(include string, scope *ListParams) (string, []interface{}) {
+	filters := make([]FilterListParameter, 0, len(scope.Filters))
+	for _, filter := range scope.Filters {
+		field := include + sqlTableFieldDelimiter + filter.Field
+		if scope.isAllowedFilter(filter.Field, filter.Operator) && !params.isAllowedFilter(field, filter.Operator) {
+			filters = append(filters, filter)
+		}
+	}
+	condition, arguments, err := scope.getFiltersCondition(filters, false)
+	if err != nil {
+		return noRecordsCondition, []interface{}{}
+	}
+	return condition, arguments
+}
+
+// limitIncludeScope limits count of included records per parent record by ROW_NUMBER window function.
+// The dialect has to support window functions
+func (params *ListParams) limitIncludeScope(include string, scope *ListParams, where string,
+	args []interface{}) (string, []interface{}) {
+	relation, ok := params.getIncludeRelation(include)
+	if !ok || relation.Kind != RelationHasMany {
+		return where, args
+	}
+	table := getTableName(scope.ObjectType)
+	primaryKey := getPrimaryKeyColumn(scope.ObjectType)
+	order := scope.GetOrderByString()
+	if order == "" {
+		order = fmt.Sprintf("%s.%s", table, primaryKey)
+	}
+	if where == "" {
+		where = "1 = 1"
+	}
+
+	limited := fmt.Sprintf("%s.%s IN (SELECT ranked.%s FROM (SELECT %s.%s, ROW_NUMBER() OVER (PARTITION BY %s.%s ORDER BY %s) AS %s "+
+		"FROM %s WHERE %s) AS ranked WHERE ranked.%s > ? AND ranked.%s <= ?)",
+		table, primaryKey, primaryKey, table, primaryKey, table, relation.ForeignKey, order, rowNumberAlias,
+		table, where, rowNumberAlias, rowNumberAlias)
+	return limited, append(args, scope.GetOffset(), scope.GetOffset()+scope.GetLimit())
+}
+
+// getIncludeRelation returns relation of the last element of include path
+func (params *ListParams) getIncludeRelation(include string) (*Relation, bool) {
+	modelType := indirectType(params.ObjectType)
+	parts := strings.Split(include, includesSeparator)
+	var relation *Relation
+	for i := range parts {
+		var ok bool
+		relation, ok = params.getRelation(modelType, strings.Join(parts[:i+1], includesSeparator))
+		if !ok {
+			return nil, false
+		}
+		modelType = relation.modelType
+	}
+	return relation, true
+}
+
+// getIncludeType returns type of related model by include path
+func (params *ListParams) getIncludeType(include string) (reflect.Type, bool) {
+	relation, ok := params.getIncludeRelation(include)
+	if !ok || relation.modelType == nil {
+		return nil, false
+	}
+	return relation.modelType, true
+}
+
+// getPrimaryKeyColumn returns column of field with gorm primary_key tag or id
+func getPrimaryKeyColumn(modelType reflect.Type) string {
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		if _, ok := parseGormTag(field.Tag.Get("gorm"))["PRIMARY_KEY"]; ok {
+			return getFieldColumn(field)
+		}
+	}
+	return getColumnName(modelType, "ID")
+}
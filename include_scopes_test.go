@@ -0,0 +1,106 @@
+package list_params
+
+import (
+	"fmt"
+	"testing"
+)
+
+func newIncludeScopeParams(query string) (*ListParams, *ListParams) {
+	params := NewListParamsFromQuery(query, account{})
+	params.AllowIncludes([]string{"transactions"})
+	scope := params.IncludeParams("transactions")
+	scope.AllowFilters([]string{"status"})
+	scope.AllowSortings([]string{"created_at"})
+	scope.AllowPagination()
+	return params, scope
+}
+
+func TestIncludeScopeConditions(t *testing.T) {
+	params, _ := newIncludeScopeParams("include=transactions&filter[transactions.status]=completed" +
+		"&sort[transactions]=-created_at")
+	if ok, errs := params.Validate(); !ok {
+		t.Fatal(errs)
+	}
+
+	if where, _ := params.GetWhereCondition(); where != "" {
+		t.Errorf("expected filter of included records only, got %s", where)
+	}
+	preloads := params.GetPreloadsWithConditions()
+	if len(preloads) != 1 {
+		t.Fatalf("expected one preload, got %v", preloads)
+	}
+	preload := preloads[0]
+	if preload.Name != "Transactions" || preload.Where != "(transactions.status = ?)" ||
+		fmt.Sprint(preload.Args) != "[[completed]]" || preload.Order != "transactions.created_at DESC" {
+		t.Errorf("unexpected preload %+v", preload)
+	}
+}
+
+func TestIncludeScopeFilterAllowedForList(t *testing.T) {
+	params, _ := newIncludeScopeParams("include=transactions&filter[transactions.status]=completed")
+	params.AllowFilters([]string{"transactions.status"})
+	if ok, errs := params.Validate(); !ok {
+		t.Fatal(errs)
+	}
+
+	if where, _ := params.GetWhereCondition(); where == "" {
+		t.Error("expected filter of the list")
+	}
+	if preload := params.GetPreloadsWithConditions()[0]; preload.Where != "" {
+		t.Errorf("expected included records not to be filtered, got %s", preload.Where)
+	}
+}
+
+func TestIncludeScopePagination(t *testing.T) {
+	params, _ := newIncludeScopeParams("include=transactions&page[transactions][size]=5&page[transactions][number]=2")
+	if ok, errs := params.Validate(); !ok {
+		t.Fatal(errs)
+	}
+
+	preload := params.GetPreloadsWithConditions()[0]
+	expected := "transactions.id IN (SELECT ranked.id FROM (SELECT transactions.id, ROW_NUMBER() OVER " +
+		"(PARTITION BY transactions.account_id ORDER BY transactions.id) AS list_params_row " +
+		"FROM transactions WHERE 1 = 1) AS ranked WHERE ranked.list_params_row > ? AND ranked.list_params_row <= ?)"
+	if preload.Where != expected || fmt.Sprint(preload.Args) != "[5 10]" {
+		t.Errorf("expected %s [5 10], got %s %v", expected, preload.Where, preload.Args)
+	}
+}
+
+func TestIncludeScopePaginationWithoutWindowFunctions(t *testing.T) {
+	dialect := Dialect("include_scopes_test")
+	DisableWindowFunctions(dialect)
+	params := NewListParamsFromQuery("include=transactions&page[transactions][size]=5", account{})
+	params.SetDialect(dialect)
+	params.AllowIncludes([]string{"transactions"})
+	params.IncludeParams("transactions").AllowPagination()
+
+	if _, err := params.GetPreloadsWithConditionsWithError(); err == nil ||
+		AsParamError(err).Code != CodePaginationNotAllowed {
+		t.Errorf("expected %s error, got %v", CodePaginationNotAllowed, err)
+	}
+	if preload := params.GetPreloadsWithConditions()[0]; preload.Where != "" {
+		t.Errorf("expected pagination to be skipped, got %s", preload.Where)
+	}
+}
+
+func TestIncludeScopeNotAllowed(t *testing.T) {
+	params := NewListParamsFromQuery("include=transactions&sort[transactions]=amount&page[transactions][size]=5",
+		account{})
+	params.AllowIncludes([]string{"transactions"})
+	params.IncludeParams("transactions")
+
+	ok, errs := params.Validate()
+	if ok || len(errs) != 2 {
+		t.Fatalf("expected errors of sorting and pagination, got %v", errs)
+	}
+}
+
+func TestIncludeParamsOfUnknownRelation(t *testing.T) {
+	params := NewListParamsFromQuery("include=cards", account{})
+	params.AllowIncludes([]string{"cards"})
+	params.IncludeParams("cards").AllowFilters([]string{"status"})
+
+	if ok, errs := params.Validate(); ok || AsParamError(errs[0]).Code != CodeIncludeRelationNotFound {
+		t.Errorf("expected %s error, got %v", CodeIncludeRelationNotFound, errs)
+	}
+}
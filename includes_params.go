@@ -1,10 +1,10 @@
 package list_params
 
 import (
-	"errors"
-	"fmt"
+	"context"
 	"net/url"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/iancoleman/strcase"
@@ -17,15 +17,25 @@ type Includes struct {
 	allowedIncludes []string
 	fieldsSet       []interface{}
 	selectedFields  []interface{}
+	countIncludes   []string
+	maxDepth        int
+	policies        map[string]OutputPolicy
+	modelType       reflect.Type
+	requiredFields  []interface{} // Keys selected by sparse fields even if they are not allowed, they are not output
 }
 
 const includesSeparator = "."
+const includesWildcard = "*"
 
 type customIncludesFunc func(records []interface{}) error
 
+type contextIncludesFunc func(ctx context.Context, records []interface{}) error
+
 type customIncludes struct {
-	Field string
-	Func  customIncludesFunc
+	Field       string
+	Func        customIncludesFunc
+	ContextFunc contextIncludesFunc
+	Loader      *Loader
 }
 
 // NewIncludes returns new Includes from URL query string
@@ -34,53 +44,100 @@ func NewIncludes(query string) *Includes {
 	if err != nil {
 		return nil
 	}
-	includes := &Includes{}
+	includes := &Includes{policies: make(map[string]OutputPolicy)}
 	includes.set(values)
 	return includes
 }
 
 // Validate returns true if params are valid.
-// Returns false with list of errors otherwise
+// Returns false with list of errors otherwise.
+// Parents of nested includes are included implicitly and have to be allowed too.
+// Wildcards are allowed in allowed patterns only, passed includes with wildcards are invalid
 func (self *Includes) Validate() (bool, []error) {
 	for _, v := range self.passedIncludes {
+		if strings.Contains(v, includesWildcard) {
+			self.addAllowingError(v)
+			continue
+		}
+		if self.maxDepth != 0 && getIncludeDepth(v) > self.maxDepth {
+			self.errors = append(self.errors, newParamError(CodeIncludeTooDeep, "include", v, "", "",
+				map[string]string{"max_depth": strconv.Itoa(self.maxDepth)}))
+			continue
+		}
 		if !self.isAllowedIncludes(v) {
 			self.addAllowingError(v)
+			continue
+		}
+		if self.isCountIncludes(v) {
+			continue
+		}
+		for _, parent := range getIncludeParents(v) {
+			if !self.isAllowedIncludes(parent) {
+				self.errors = append(self.errors, newParamError(CodeIncludeParentNotAllowed, "include", v, "", "",
+					map[string]string{"parent": parent}))
+				break
+			}
 		}
 	}
 	return len(self.errors) == 0, self.errors
 }
 
+// SetMaxDepth limits count of relations in include path. Example: author.likes has depth 2.
+// Zero means no limit
+func (self *Includes) SetMaxDepth(depth int) {
+	self.maxDepth = depth
+}
+
 func (self *Includes) AddIncludes(name string) {
 	self.passedIncludes = append(self.passedIncludes, name)
 }
 
-// GetCustomIncludesFunctions returns list of customIncludesFunc needed to apply
+// GetCustomIncludesFunctions returns list of customIncludesFunc needed to apply.
+// Includes added by AddIncludeLoader are skipped, use ListParams.LoadIncludes to load them
 func (self *Includes) GetCustomIncludesFunctions() []customIncludesFunc {
 	functions := make([]customIncludesFunc, 0)
 	for _, customIncludes := range self.customIncludes {
-		if self.isIncluded(customIncludes.Field) {
+		if customIncludes.Func != nil && self.isIncluded(customIncludes.Field) {
 			functions = append(functions, customIncludes.Func)
 		}
 	}
 	return functions
 }
 
-// Allow sets list of allowed fields
+// Allow sets list of allowed fields.
+// Wildcard * matches any relation on its level. Example: author.* allows author.likes and author.posts.
+// Allowed nested field allows its parents, except parents containing wildcards:
+// author.likes allows author, *.likes allows nothing but likes of any relation
 func (self *Includes) Allow(fields []string) {
-	self.allowedIncludes = fields
+	self.allowedIncludes = append([]string(nil), fields...)
 }
 
 // AddCustomIncludes adds custom includes func for specific field
 func (self *Includes) AddCustomIncludes(field string, function customIncludesFunc) {
-	self.customIncludes = append(self.customIncludes, customIncludes{field, function})
+	self.customIncludes = append(self.customIncludes, customIncludes{Field: field, Func: function})
+}
+
+// AddContextIncludes adds custom includes func called with context for specific field
+func (self *Includes) AddContextIncludes(field string, function contextIncludesFunc) {
+	self.customIncludes = append(self.customIncludes, customIncludes{Field: field, ContextFunc: function})
 }
 
 // GetOutputFields returns fields needed to be serialized.
 // Depends on passed includes
 func (i *Includes) GetOutputFields() []interface{} {
+	return i.getOutputFields(false)
+}
+
+// getOutputFields returns fields needed to be serialized and with required keys fields needed to be selected
+func (i *Includes) getOutputFields(withRequired bool) []interface{} {
 	var fieldToDisplay []interface{}
 	if len(i.selectedFields) > 0 {
-		fieldToDisplay = getFieldsToDisplay(i.fieldsSet, i.selectedFields)
+		allowed := InterfaceArrayToFields(i.fieldsSet)
+		displayed := allowed.Intersect(resolveSelectedFields(i.selectedFields, allowed))
+		if withRequired {
+			displayed = displayed.Merge(InterfaceArrayToFields(i.requiredFields))
+		}
+		fieldToDisplay = displayed.ToInterfaceArray()
 	} else {
 		fieldToDisplay = i.fieldsSet
 	}
@@ -88,9 +145,20 @@ func (i *Includes) GetOutputFields() []interface{} {
 	return i.getOutputFieldsRecursively(fieldToDisplay, []string{})
 }
 
-// AllowSelectFields sets all possible fields can be serialized
+// SetModel sets model used to resolve wildcards of allowed fields.
+// ListParams.AllowSelectFields sets type of its object
+func (self *Includes) SetModel(model interface{}) {
+	self.modelType = reflect.TypeOf(model)
+}
+
+// AllowSelectFields sets all possible fields can be serialized.
+// Fields can have output policies, see WithPolicy.
+// Wildcard * allows all columns of the model on its level, -field excludes the field
+// and relation.* allows all columns of the relation. Example: *, -password, author.*.
+// Model has to be set by SetModel before, otherwise wildcards allow nothing
 func (self *Includes) AllowSelectFields(fieldsSet []interface{}) {
-	self.fieldsSet = fieldsSet
+	self.policies = make(map[string]OutputPolicy)
+	self.fieldsSet = self.resolveAllowedFields(self.setOutputPolicies(fieldsSet, ""))
 }
 
 // GetPreloads returns list of needed preloads for eager loading
@@ -103,6 +171,9 @@ func (self *Includes) GetPreloads() []string {
 	return preloads
 }
 
+// SelectFields sets fields to select in format of AllowSelectFields.
+// Wildcard * selects all allowed fields on its level including relations, -field excludes the field
+// and relation.* selects all allowed fields of the relation
 func (i *Includes) SelectFields(fields []interface{}) {
 	i.selectedFields = fields
 }
@@ -111,7 +182,7 @@ func (i *Includes) GetSelectQuery() []string {
 	if len(i.fieldsSet) == 0 {
 		return []string{"*"}
 	}
-	return i.selectQueryRecursively(i.GetOutputFields(), []string{})
+	return i.selectQueryRecursively(i.getOutputFields(true), []string{})
 }
 
 func (i *Includes) selectQueryRecursively(fields []interface{}, rootElements []string) []string {
@@ -148,7 +219,7 @@ func (self *Includes) transformPreloading(field string) string {
 func (self *Includes) getNotCustomIncludes() []string {
 	notCustom := make([]string, 0)
 	for _, includes := range self.passedIncludes {
-		if !self.isCustomIncludes(includes) && self.isIncluded(includes) {
+		if !self.isCustomIncludes(includes) && !self.isCountIncludes(includes) && self.isIncluded(includes) {
 			notCustom = append(notCustom, includes)
 		}
 	}
@@ -164,6 +235,20 @@ func (self *Includes) isCustomIncludes(field string) bool {
 	return false
 }
 
+// addCountIncludes adds includes of related records count. Count includes are allowed and not preloaded
+func (self *Includes) addCountIncludes(field string) {
+	self.countIncludes = append(self.countIncludes, field)
+}
+
+func (self *Includes) isCountIncludes(field string) bool {
+	for _, v := range self.countIncludes {
+		if v == field {
+			return true
+		}
+	}
+	return false
+}
+
 func (self *Includes) set(values url.Values) {
 	list := make([]string, 0)
 	fields := values["include"]
@@ -179,99 +264,67 @@ func (self *Includes) set(values url.Values) {
 }
 
 func (self *Includes) addAllowingError(field string) {
-	text := fmt.Sprintf("Including of %s in not allowed", field)
-	err := errors.New(text)
-	self.errors = append(self.errors, err)
+	self.errors = append(self.errors, newIncludesError(field))
+}
+
+func newIncludesError(field string) *ParamError {
+	return newParamError(CodeIncludeNotAllowed, "include", field, "", "", nil)
 }
 
 func (self *Includes) isAllowedIncludes(field string) bool {
 	for _, v := range self.allowedIncludes {
-		if v == field {
+		if matchIncludePattern(v, field) || (isImpliedParent(v, field) && !self.isCountIncludes(v)) {
 			return true
 		}
 	}
 	return false
 }
 
+// isIncluded returns true if the relation or any of its nested relations is passed
 func (self *Includes) isIncluded(relation string) bool {
 	for _, v := range self.passedIncludes {
 		if v == relation {
 			return true
 		}
+		if strings.HasPrefix(v, relation+includesSeparator) && !self.isCountIncludes(v) {
+			return true
+		}
 	}
 	return false
 }
 
-func getFieldsToDisplay(allowedFields []interface{}, selectedFields []interface{}) []interface{} {
-	result := make([]interface{}, 0)
-	for _, key := range allowedFields {
-		if reflect.ValueOf(key).Kind() == reflect.String {
-			if hasSelectedFieldStr(key, selectedFields) {
-				result = append(result, key)
-			}
-			continue
-		}
-
-		allowedMap := key.(map[string][]interface{})
-		if selectedMap := getAllowedSelectedMap(allowedMap, selectedFields); len(selectedMap) != 0 {
-			result = append(result, selectedMap)
-		}
+// matchIncludePattern returns true if the include matches allowed pattern
+func matchIncludePattern(pattern string, include string) bool {
+	patternParts := strings.Split(pattern, includesSeparator)
+	includeParts := strings.Split(include, includesSeparator)
+	if len(patternParts) != len(includeParts) {
+		return false
 	}
-
-	return result
-}
-
-func getAllowedSelectedMap(allowedMap map[string][]interface{}, selectedFields []interface{}) map[string][]interface{} {
-	result := make(map[string][]interface{}, 0)
-	for key, value := range allowedMap {
-		if innerSelectedFields := getSelectedFieldsByMapKey(key, selectedFields); innerSelectedFields != nil {
-			result[key] = getFieldsToDisplay(value, innerSelectedFields)
+	for i, part := range patternParts {
+		if part != includesWildcard && part != includeParts[i] {
+			return false
 		}
 	}
-	return result
+	return true
 }
 
-func getSelectedFieldsByMapKey(mapKey string, selectedFields []interface{}) []interface{} {
-	for _, mapField := range selectedFields {
-		if reflect.ValueOf(mapField).Kind() == reflect.Map {
-			originalSelectedMap := mapField.(map[string][]interface{})
-			for k, v := range originalSelectedMap {
-				if k == mapKey {
-					return v
-				}
-			}
-		}
-	}
-	return nil
+// isImpliedParent returns true if the include is a parent of allowed pattern without wildcards
+func isImpliedParent(pattern string, include string) bool {
+	return strings.HasPrefix(pattern, include+includesSeparator) && !strings.Contains(include, includesWildcard)
 }
 
-func hasSelectedFieldStr(elem interface{}, fields []interface{}) bool {
-	for _, field := range fields {
-		if reflect.ValueOf(field).Kind() == reflect.String && field == elem {
-			return true
-		}
+// getIncludeParents returns parents of nested include. Example: author, author.likes for author.likes.comments
+func getIncludeParents(include string) []string {
+	parts := strings.Split(include, includesSeparator)
+	parents := make([]string, len(parts)-1)
+	for i := range parents {
+		parents[i] = strings.Join(parts[:i+1], includesSeparator)
 	}
-	return false
+	return parents
 }
 
-func selectedFieldMap(elem interface{}, fields []interface{}) (mapKey string, mapFields []interface{}) {
-	for _, field := range fields {
-		if reflect.ValueOf(field).Kind() == reflect.Map && isMapHasKey(elem, field) {
-			mapKey = elem.(string)
-			mapFields = field.(map[string][]interface{})[mapKey]
-			return
-		}
-	}
-	return "", nil
-}
-
-func isMapHasKey(elem interface{}, mapField interface{}) bool {
-	for key := range mapField.(map[string][]interface{}) {
-		if key == elem {
-			return true
-		}
-	}
-	return false
+func getIncludeDepth(include string) int {
+	return len(strings.Split(include, includesSeparator))
 }
 
 func (self *Includes) getOutputFieldsRecursively(fieldsSet []interface{}, rootFields []string) []interface{} {
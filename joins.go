@@ -0,0 +1,178 @@
+package list_params
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// JoinClause describes join with optional alias and arguments of ON statement.
+// Can be added by AddJoinClause or declared together with custom filter or sorting
+type JoinClause struct {
+	Type  Join
+	Table string // Table name or subquery in parentheses for lateral joins
+	Alias string
+	On    string // ON statement. Can contain placeholders for Args. Not used for cross joins
+	Args  []interface{}
+}
+
+// LeftJoin returns JoinClause for LEFT JOIN
+func LeftJoin(table string, on string, args ...interface{}) JoinClause {
+	return JoinClause{Type: JoinLeft, Table: table, On: on, Args: args}
+}
+
+// RightJoin returns JoinClause for RIGHT JOIN
+func RightJoin(table string, on string, args ...interface{}) JoinClause {
+	return JoinClause{Type: JoinRight, Table: table, On: on, Args: args}
+}
+
+// InnerJoin returns JoinClause for INNER JOIN
+func InnerJoin(table string, on string, args ...interface{}) JoinClause {
+	return JoinClause{Type: JoinInner, Table: table, On: on, Args: args}
+}
+
+// FullJoin returns JoinClause for FULL JOIN
+func FullJoin(table string, on string, args ...interface{}) JoinClause {
+	return JoinClause{Type: JoinFull, Table: table, On: on, Args: args}
+}
+
+// CrossJoin returns JoinClause for CROSS JOIN
+func CrossJoin(table string, args ...interface{}) JoinClause {
+	return JoinClause{Type: JoinCross, Table: table, Args: args}
+}
+
+// LateralJoin returns JoinClause for LEFT JOIN LATERAL.
+// Subquery should be in parentheses, ON TRUE is used if on is empty
+func LateralJoin(subquery string, on string, args ...interface{}) JoinClause {
+	return JoinClause{Type: JoinLateral, Table: subquery, On: on, Args: args}
+}
+
+// CrossLateralJoin returns JoinClause for CROSS JOIN LATERAL
+func CrossLateralJoin(subquery string, args ...interface{}) JoinClause {
+	return JoinClause{Type: JoinCrossLateral, Table: subquery, Args: args}
+}
+
+// As returns copy of the clause with alias.
+// Aliases allow to join the same table several times
+func (c JoinClause) As(alias string) JoinClause {
+	c.Alias = alias
+	return c
+}
+
+// AddJoinClause adds join. Support of join type by the dialect is checked by GetJoinConditionWithArgs
+func (params *ListParams) AddJoinClause(clause JoinClause) {
+	j := clause.toJoin()
+	if !params.isJoinExist(j) {
+		params.joins = append(params.joins, j)
+	}
+}
+
+// GetJoinConditionWithArgs returns SQL string with joins and arguments of ON statements.
+// Returns error if type of any added join or join of used custom filter or sorting
+// is not supported by the dialect, so the dialect has to be set before
+func (params *ListParams) GetJoinConditionWithArgs() (string, []interface{}, error) {
+	joins := params.getJoins()
+	joinParts := make([]string, len(joins))
+	args := make([]interface{}, 0)
+	for i, j := range joins {
+		if !params.dialect.SupportsJoin(j.joinType) {
+			return "", nil, fmt.Errorf("%s JOIN is not supported by %s", j.joinType, params.dialect)
+		}
+		joinParts[i] = j.sql()
+		args = append(args, j.args...)
+	}
+	return strings.Join(joinParts, " "), args, nil
+}
+
+// GetArguments returns arguments of joins followed by arguments of where condition.
+// Arguments are ordered as placeholders of joins and where statement in a query
+func (params *ListParams) GetArguments() []interface{} {
+	_, joinArgs, _ := params.GetJoinConditionWithArgs()
+	_, whereArgs := params.GetWhereCondition()
+	return append(joinArgs, whereArgs...)
+}
+
+func (c JoinClause) toJoin() join {
+	return join{tableName: c.Table, alias: c.Alias, onStatement: c.On, args: c.Args, joinType: c.Type}
+}
+
+func joinClausesToJoins(clauses []JoinClause) []join {
+	joins := make([]join, len(clauses))
+	for i, clause := range clauses {
+		joins[i] = clause.toJoin()
+	}
+	return joins
+}
+
+// sql returns SQL string of the join
+func (j join) sql() string {
+	target := j.tableName
+	if j.alias != "" {
+		target = fmt.Sprintf("%s AS %s", j.tableName, j.alias)
+	}
+	switch j.joinType {
+	case JoinCross:
+		return fmt.Sprintf("CROSS JOIN %s", target)
+	case JoinCrossLateral:
+		return fmt.Sprintf("CROSS JOIN LATERAL %s", target)
+	case JoinLateral:
+		on := j.onStatement
+		if on == "" {
+			on = "TRUE"
+		}
+		return fmt.Sprintf("LEFT JOIN LATERAL %s ON %s", target, on)
+	}
+	return fmt.Sprintf("%s JOIN %s ON %s", j.joinType, target, j.onStatement)
+}
+
+// getJoins returns added joins, joins of used custom filters and sortings
+// and joins of relationships used by filters and sortings without duplicates
+func (params *ListParams) getJoins() []join {
+	joins := params.getAddedJoins()
+	for _, j := range params.getRelationJoins() {
+		if !isJoinInList(joins, j) {
+			joins = append(joins, j)
+		}
+	}
+	return joins
+}
+
+// getAddedJoins returns added joins and joins of used custom filters and sortings without duplicates
+func (params *ListParams) getAddedJoins() []join {
+	joins := make([]join, 0, len(params.joins))
+	add := func(list []join) {
+		for _, j := range list {
+			if !isJoinInList(joins, j) {
+				joins = append(joins, j)
+			}
+		}
+	}
+
+	add(params.joins)
+	for _, filter := range params.Filters {
+		if custom := params.getCustomFilter(filter.Field); custom != nil {
+			add(custom.Joins)
+		}
+	}
+	for _, enforced := range params.enforcedFilters {
+		if custom := params.getCustomFilter(enforced.Field); custom != nil {
+			add(custom.Joins)
+		}
+	}
+	for _, sorting := range params.Sortings {
+		if custom := params.getCustomSorting(sorting.Field); custom != nil {
+			add(custom.Joins)
+		}
+	}
+	return joins
+}
+
+func isJoinInList(list []join, j join) bool {
+	for _, v := range list {
+		if v.tableName == j.tableName && v.alias == j.alias && v.onStatement == j.onStatement &&
+			v.joinType == j.joinType && reflect.DeepEqual(v.args, j.args) {
+			return true
+		}
+	}
+	return false
+}
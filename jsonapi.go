@@ -0,0 +1,346 @@
+package list_params
+
+import (
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const jsonAPITagName = "jsonapi"
+const jsonAPIPrimaryTag = "primary"
+const jsonAPIRelationTag = "relation"
+
+var (
+	valuerType        = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+var jsonAPITypes = struct {
+	sync.RWMutex
+	types map[reflect.Type]string
+}{types: make(map[reflect.Type]string)}
+
+// JSONAPIDocument is JSON:API compound document with list of resources
+type JSONAPIDocument struct {
+	Data     []*JSONAPIResource `json:"data"`
+	Included []*JSONAPIResource `json:"included,omitempty"`
+	Meta     PaginationMeta     `json:"meta"`
+	Links    Links              `json:"links"`
+}
+
+// JSONAPIResource is JSON:API resource object
+type JSONAPIResource struct {
+	Type          string                          `json:"type"`
+	ID            string                          `json:"id"`
+	Attributes    map[string]interface{}          `json:"attributes,omitempty"`
+	Relationships map[string]*JSONAPIRelationship `json:"relationships,omitempty"`
+}
+
+// JSONAPIRelationship is JSON:API relationship object.
+// Data is *JSONAPIResourceIdentifier, slice of them or nil
+type JSONAPIRelationship struct {
+	Data interface{} `json:"data"`
+}
+
+// JSONAPIResourceIdentifier is JSON:API resource identifier object
+type JSONAPIResourceIdentifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// RegisterJSONAPIType sets JSON:API type of the model.
+// Type can also be set by tag of primary key field: `jsonapi:"primary,accounts"`.
+// Table name of the model is used by default
+func RegisterJSONAPIType(model interface{}, typeName string) {
+	jsonAPITypes.Lock()
+	defer jsonAPITypes.Unlock()
+	jsonAPITypes.types[indirectType(reflect.TypeOf(model))] = typeName
+}
+
+// NewJSONAPIDocument creates JSON:API document for records loaded by params.
+// Included relations are taken from requested includes and output fields are used as attributes.
+// Relationship names are taken from tag `jsonapi:"relation,name"` or json tag.
+// Related records presented in data are not added to included resources.
+// total is count of all records matching filters, path is path of the list endpoint
+func NewJSONAPIDocument(records interface{}, params *ListParams, total int64, path string) (*JSONAPIDocument, error) {
+	renderer := &jsonAPIRenderer{params: params, included: make(map[string]bool)}
+	document := &JSONAPIDocument{
+		Data:  make([]*JSONAPIResource, 0),
+		Meta:  params.GetPaginationMeta(total),
+		Links: params.GetLinks(path, total),
+	}
+	slice := reflect.Indirect(reflect.ValueOf(records))
+	for i := 0; i < slice.Len(); i++ {
+		resource, err := renderer.resource(slice.Index(i), params.GetOutputFields(), "")
+		if err != nil {
+			return nil, err
+		}
+		if resource != nil {
+			document.Data = append(document.Data, resource)
+		}
+	}
+	primary := make(map[string]bool, len(document.Data))
+	for _, resource := range document.Data {
+		primary[resource.key()] = true
+	}
+	for _, resource := range renderer.resources {
+		if !primary[resource.key()] {
+			document.Included = append(document.Included, resource)
+		}
+	}
+	return document, nil
+}
+
+// RenderJSONAPIDocument returns JSON:API document as JSON, see NewJSONAPIDocument.
+// Should be sent with JSONAPIContentType
+func RenderJSONAPIDocument(records interface{}, params *ListParams, total int64, path string) ([]byte, error) {
+	document, err := NewJSONAPIDocument(records, params, total, path)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(document)
+}
+
+type jsonAPIRenderer struct {
+	params    *ListParams
+	included  map[string]bool
+	resources []*JSONAPIResource
+}
+
+// resource returns resource object of the record and adds its included relations to included resources
+func (r *jsonAPIRenderer) resource(value reflect.Value, fields []interface{}, path string) (*JSONAPIResource, error) {
+	value, ok := indirectValue(value)
+	if !ok {
+		return nil, nil
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Can not render %s as JSON:API resource", value.Type())
+	}
+	resource := &JSONAPIResource{
+		Type:          getJSONAPIType(value.Type()),
+		ID:            getJSONAPIID(value),
+		Relationships: make(map[string]*JSONAPIRelationship),
+	}
+
+	relations := r.getIncludedRelations(value.Type(), path)
+	attributes, err := r.attributes(value, fields, path, relations)
+	if err != nil {
+		return nil, err
+	}
+	resource.Attributes = attributes
+
+	for include, field := range relations {
+		nestedFields := getNestedOutputFields(fields, field.Name, include)
+		if nestedFields == nil && len(r.params.Includes.fieldsSet) != 0 {
+			continue
+		}
+		relationship, err := r.relationship(value.FieldByIndex(field.Index), nestedFields, joinFieldPath(path, include))
+		if err != nil {
+			return nil, err
+		}
+		resource.Relationships[getJSONAPIRelationName(field)] = relationship
+	}
+	if len(resource.Relationships) == 0 {
+		resource.Relationships = nil
+	}
+	return resource, nil
+}
+
+// attributes returns output fields of the record except primary key and relations
+func (r *jsonAPIRenderer) attributes(value reflect.Value, fields []interface{}, path string,
+	relations map[string]reflect.StructField) (map[string]interface{}, error) {
+	var attributes map[string]interface{}
+	if len(r.params.Includes.fieldsSet) != 0 {
+		attributes = r.params.Includes.projectStruct(value, getStringFields(fields), path)
+	} else {
+		var err error
+		if attributes, err = marshalToMap(value.Interface()); err != nil {
+			return nil, err
+		}
+		for i := 0; i < value.NumField(); i++ {
+			if field := value.Type().Field(i); !field.Anonymous && isRelationType(field.Type) {
+				delete(attributes, projectedKey(field))
+			}
+		}
+	}
+	for _, field := range relations {
+		delete(attributes, projectedKey(field))
+	}
+	if primaryKey, ok := getJSONAPIPrimaryField(value.Type()); ok {
+		delete(attributes, projectedKey(primaryKey))
+	}
+	return attributes, nil
+}
+
+// relationship returns relationship with identifiers of related records and adds them to included resources
+func (r *jsonAPIRenderer) relationship(value reflect.Value, fields []interface{}, path string) (*JSONAPIRelationship, error) {
+	value, ok := indirectValue(value)
+	if !ok {
+		return &JSONAPIRelationship{Data: nil}, nil
+	}
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		identifier, err := r.include(value, fields, path)
+		return &JSONAPIRelationship{Data: identifier}, err
+	}
+	identifiers := make([]*JSONAPIResourceIdentifier, 0, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		identifier, err := r.include(value.Index(i), fields, path)
+		if err != nil {
+			return nil, err
+		}
+		if identifier != nil {
+			identifiers = append(identifiers, identifier)
+		}
+	}
+	return &JSONAPIRelationship{Data: identifiers}, nil
+}
+
+// include adds related record to included resources once and returns its identifier
+func (r *jsonAPIRenderer) include(value reflect.Value, fields []interface{}, path string) (*JSONAPIResourceIdentifier, error) {
+	resource, err := r.resource(value, fields, path)
+	if err != nil || resource == nil {
+		return nil, err
+	}
+	key := resource.key()
+	if !r.included[key] {
+		r.included[key] = true
+		r.resources = append(r.resources, resource)
+	}
+	return &JSONAPIResourceIdentifier{Type: resource.Type, ID: resource.ID}, nil
+}
+
+// getIncludedRelations returns struct fields of relations included on the path by names of includes
+func (r *jsonAPIRenderer) getIncludedRelations(modelType reflect.Type, path string) map[string]reflect.StructField {
+	result := make(map[string]reflect.StructField)
+	includes := r.params.Includes
+	prefix := ""
+	if path != "" {
+		prefix = path + includesSeparator
+	}
+	for _, include := range includes.passedIncludes {
+		if includes.isCountIncludes(include) || !strings.HasPrefix(include, prefix) {
+			continue
+		}
+		name := strings.Split(strings.TrimPrefix(include, prefix), includesSeparator)[0]
+		if field, ok := findStructField(modelType, name); ok {
+			result[name] = field
+		}
+	}
+	return result
+}
+
+// getNestedOutputFields returns output fields of relation. Returns nil if select fields are not allowed
+func getNestedOutputFields(fields []interface{}, fieldName string, include string) []interface{} {
+	for _, field := range fields {
+		nested, ok := field.(map[string][]interface{})
+		if !ok {
+			continue
+		}
+		for key, values := range nested {
+			if key == fieldName || normalizeFieldPath(key) == normalizeFieldPath(include) {
+				return values
+			}
+		}
+	}
+	return nil
+}
+
+func getStringFields(fields []interface{}) []interface{} {
+	result := make([]interface{}, 0, len(fields))
+	for _, field := range fields {
+		if _, ok := field.(string); ok {
+			result = append(result, field)
+		}
+	}
+	return result
+}
+
+// getJSONAPIType returns type of the model from registry, tag of primary key or table name
+func getJSONAPIType(modelType reflect.Type) string {
+	jsonAPITypes.RLock()
+	typeName, ok := jsonAPITypes.types[modelType]
+	jsonAPITypes.RUnlock()
+	if ok {
+		return typeName
+	}
+	if field, ok := getJSONAPIPrimaryField(modelType); ok {
+		if parts := strings.Split(field.Tag.Get(jsonAPITagName), ","); len(parts) > 1 && parts[1] != "" {
+			return parts[1]
+		}
+	}
+	return getTableName(modelType)
+}
+
+// getJSONAPIID returns value of primary key as string
+func getJSONAPIID(value reflect.Value) string {
+	field, ok := getJSONAPIPrimaryField(value.Type())
+	if !ok {
+		return ""
+	}
+	id, ok := indirectValue(value.FieldByIndex(field.Index))
+	if !ok {
+		return ""
+	}
+	return fmt.Sprint(id.Interface())
+}
+
+// getJSONAPIPrimaryField returns field with jsonapi primary tag, gorm primary_key tag or ID field
+func getJSONAPIPrimaryField(modelType reflect.Type) (reflect.StructField, bool) {
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		if strings.Split(field.Tag.Get(jsonAPITagName), ",")[0] == jsonAPIPrimaryTag {
+			return field, true
+		}
+	}
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		if _, ok := parseGormTag(field.Tag.Get("gorm"))["PRIMARY_KEY"]; ok {
+			return field, true
+		}
+	}
+	return modelType.FieldByName("ID")
+}
+
+// getJSONAPIRelationName returns relationship name from jsonapi relation tag or json tag
+func getJSONAPIRelationName(field reflect.StructField) string {
+	parts := strings.Split(field.Tag.Get(jsonAPITagName), ",")
+	if parts[0] == jsonAPIRelationTag && len(parts) > 1 && parts[1] != "" {
+		return parts[1]
+	}
+	return projectedKey(field)
+}
+
+// key returns key of the resource unique by type and id
+func (resource *JSONAPIResource) key() string {
+	return resource.Type + "/" + resource.ID
+}
+
+// isRelationType returns true for structs and slices of structs except time and value types
+// such as decimals and nullable sql types, see isValueType
+func isRelationType(fieldType reflect.Type) bool {
+	relatedType := indirectType(fieldType)
+	return relatedType.Kind() == reflect.Struct && relatedType != timeType && !isValueType(relatedType)
+}
+
+// isValueType returns true if the type or pointer to it implements driver.Valuer, sql.Scanner,
+// json.Marshaler or encoding.TextMarshaler
+func isValueType(valueType reflect.Type) bool {
+	pointerType := reflect.PtrTo(valueType)
+	return pointerType.Implements(valuerType) || pointerType.Implements(scannerType) ||
+		pointerType.Implements(jsonMarshalerType) || pointerType.Implements(textMarshalerType)
+}
+
+func indirectValue(value reflect.Value) (reflect.Value, bool) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return value, false
+		}
+		value = value.Elem()
+	}
+	return value, value.IsValid()
+}
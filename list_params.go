@@ -1,6 +1,7 @@
 package list_params
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/url"
@@ -8,9 +9,9 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/iancoleman/strcase"
-	"github.com/jinzhu/inflection"
 )
 
 const AscDirection = "ASC"
@@ -21,12 +22,17 @@ const DefaultPageNumber = 1
 const DefaultPageSize = 20
 
 const (
-	JoinLeft  = Join("LEFT")
-	JoinRight = Join("RIGHT")
-	JoinInner = Join("INNER")
+	JoinLeft         = Join("LEFT")
+	JoinRight        = Join("RIGHT")
+	JoinInner        = Join("INNER")
+	JoinFull         = Join("FULL")
+	JoinCross        = Join("CROSS")
+	JoinLateral      = Join("LEFT LATERAL")
+	JoinCrossLateral = Join("CROSS LATERAL")
 )
 
 const tableNameFuncName = "TableName"
+const noRecordsCondition = "1 = 0"
 const sqlTableFieldDelimiter = "."
 
 type Join string
@@ -56,37 +62,65 @@ type ListParams struct {
 	Filters    []FilterListParameter
 	Includes   *Includes // Fields in model. Example: likes, author, author.likes
 	Pagination PaginationListParameter
-
-	ObjectType        reflect.Type
-	allowedListParams allowedListParams
-	customFilters     []customFilter
-	customIncludes    []customIncludes
-	customSortings    []customSoting
-	errors            []error
-	joins             []join
-	groupBy           *string
+	Groups     []string              // Fields to group by. Example: currency, status
+	Aggregates []Aggregate           // Aggregates of groups. Example: sum(amount), count(*)
+	Havings    []FilterListParameter // Filters by aliases of aggregates. Example: having[sum_amount:gt]=1000
+
+	ObjectType          reflect.Type
+	allowedListParams   allowedListParams
+	customFilters       []customFilter
+	customIncludes      []customIncludes
+	customSortings      []customSoting
+	filterValidators    []filterValidator
+	errors              []error
+	catalog             Catalog
+	joins               []join
+	relations           map[string]Relation
+	quantifiers         map[string]Quantifier
+	countableRelations  []string
+	groupBy             *string
+	dialect             Dialect
+	rawQuery            url.Values
+	verifyIncludes      bool
+	includesParallelism int
+	ctx                 context.Context
+	enforcedFilters     []enforcedFilter
+	conflictPolicy      ConflictPolicy
+	roleProfiles        map[string]RoleProfile
+	rolesApplied        bool
+	sparseFields        map[string][]string
+	includeScopes       map[string]*ListParams
+	timezone            *time.Location
 }
 
 type join struct {
 	tableName   string
+	alias       string
 	onStatement string
+	args        []interface{}
 	joinType    Join
 }
 
 type customFilter struct {
-	Field string
-	Func  customFilterFunc
+	Field       string
+	Func        customFilterFunc
+	ContextFunc contextFilterFunc
+	Joins       []join
 }
 
 type customSoting struct {
-	Field string
-	Func  customSortingFunc
+	Field       string
+	Func        customSortingFunc
+	ContextFunc contextSortingFunc
+	Joins       []join
 }
 
 type allowedListParams struct {
 	Sortings   []string
 	Filters    []FieldOperatorPair
 	Pagination bool
+	Groups     []string
+	Aggregates []Aggregate
 }
 
 type customFilterFunc func(inputValues []string, params *ListParams) (
@@ -94,6 +128,11 @@ type customFilterFunc func(inputValues []string, params *ListParams) (
 
 type customSortingFunc func(direction string, params *ListParams) (orderByPart string, err error)
 
+type contextFilterFunc func(ctx context.Context, inputValues []string, params *ListParams) (
+	dbConditionPart string, dbValues []interface{}, err error)
+
+type contextSortingFunc func(ctx context.Context, direction string, params *ListParams) (orderByPart string, err error)
+
 // NewListParamsFromQuery creates new ListParams from passed url query
 // and type of serialized object
 func NewListParamsFromQuery(query string, object interface{}) *ListParams {
@@ -111,14 +150,21 @@ func NewListParamsFromQuery(query string, object interface{}) *ListParams {
 	}
 
 	if err != nil {
-		listParams.addError("Query format is invalid")
+		listParams.addError(newParamError(CodeInvalidQuery, "", "", "", "", nil))
 		return listParams
 	}
 
+	listParams.rawQuery = values
 	listParams.setSortingParams(values)
 	listParams.setFilters(values)
+	listParams.setQuantifiers(values)
 	listParams.Includes = NewIncludes(query)
 	listParams.setPagination(values)
+	listParams.setSparseFields(values)
+	listParams.setGroups(values)
+	listParams.setAggregates(values)
+	listParams.setHavings(values)
+	listParams.setTimezone(values)
 
 	return listParams
 }
@@ -126,31 +172,85 @@ func NewListParamsFromQuery(query string, object interface{}) *ListParams {
 // NewListParams returns new empty ListParams
 func NewListParams() *ListParams {
 	listParams := ListParams{allowedListParams: newAllowedListParams(),
-		customFilters:  make([]customFilter, 0),
-		customSortings: make([]customSoting, 0),
-		errors:         make([]error, 0),
-		joins:          make([]join, 0),
-		Includes:       NewIncludes(""),
-		groupBy:        nil,
+		customFilters:      make([]customFilter, 0),
+		customSortings:     make([]customSoting, 0),
+		filterValidators:   make([]filterValidator, 0),
+		errors:             make([]error, 0),
+		catalog:            DefaultCatalog,
+		joins:              make([]join, 0),
+		relations:          make(map[string]Relation),
+		quantifiers:        make(map[string]Quantifier),
+		countableRelations: make([]string, 0),
+		includeScopes:      make(map[string]*ListParams),
+		roleProfiles:       make(map[string]RoleProfile),
+		sparseFields:       make(map[string][]string),
+		Includes:           NewIncludes(""),
+		groupBy:            nil,
 	}
 	return &listParams
 }
 
-// Validate checks if all passed options was allowed
+// Validate checks if all passed options was allowed.
+// Valid fields requested by fields params are selected, replacing fields set by SelectFields before
 func (params *ListParams) Validate() (bool, []error) {
+	params.applyContextRoles()
 	for _, v := range params.Sortings {
+		if params.getAggregateByAlias(v.Field) != nil {
+			continue
+		}
 		if !params.isAllowedSorting(v.Field) {
 			params.addSortingError(v.Field)
+			continue
+		}
+		if params.isMaskedField(v.Field) {
+			params.addError(newParamError(CodeSortMasked, "sort", v.Field, "", "", nil))
+			continue
+		}
+		if !params.isCountableField(v.Field) {
+			params.addError(newCountRelationError("sort", v.Field))
+			continue
+		}
+		if custom := params.getCustomSorting(v.Field); custom != nil && custom.ContextFunc != nil {
+			if _, err := params.getOrderByString(&v); err != nil {
+				params.addError(newParamError(CodeSortRejected, "sort", v.Field, "", "",
+					map[string]string{"reason": err.Error()}))
+			}
 		}
 	}
 	for _, v := range params.Filters {
+		if params.isIncludeScopeFilter(&v) {
+			continue
+		}
+		if params.isEnforcedConflict(&v) {
+			params.addEnforcedError(&v)
+			continue
+		}
 		if !params.isAllowedFilter(v.Field, v.Operator) {
 			params.addFilterError(v.Field, v.Operator)
+			continue
+		}
+		if !params.isCountableField(v.Field) {
+			params.addError(newCountRelationError(filterParameter(v.Field, v.Operator), v.Field))
+			continue
+		}
+		if params.isMaskedField(v.Field) {
+			params.addError(newParamError(CodeFilterMasked, filterParameter(v.Field, v.Operator),
+				v.Field, v.Operator, "", nil))
+			continue
+		}
+		if err := params.validateFilterValues(&v); err != nil {
+			params.addFilterValueError(err)
 		}
 	}
+	params.validateQuantifiers()
+	params.validateCountIncludes()
+	params.validateAggregates()
+	params.validateIncludeScopes()
 	if ok, errors := params.Includes.Validate(); !ok {
-		params.addErrors(errors)
+		params.addErrors(params.forbidIncludesErrors(errors))
 	}
+	params.validateIncludeRelations()
+	params.applySparseFields()
 	if !params.allowedListParams.Pagination {
 		pagination := params.Pagination
 		if pagination.PageNumber != DefaultPageNumber || (pagination.PageSize != DefaultPageSize && pagination.PageSize != 0) {
@@ -178,7 +278,7 @@ func (params *ListParams) AddInnerJoin(tableName string, onStatement string) {
 
 // AddJoin adds needed joinType for complex options
 func (params *ListParams) AddJoin(tableName string, onStatement string, joinType Join) {
-	j := join{tableName, onStatement, joinType}
+	j := join{tableName: tableName, onStatement: onStatement, joinType: joinType}
 	if !params.isJoinExist(j) {
 		params.joins = append(params.joins, j)
 	}
@@ -197,9 +297,19 @@ func (params *ListParams) AddFilter(field string, values []string, operator ...O
 
 // AddCustomFilter adds custom filter.
 // If passed field is overridden by custom filter
-// then custom filter will be used by calling customFilterFunc
-func (params *ListParams) AddCustomFilter(field string, function customFilterFunc) {
-	params.customFilters = append(params.customFilters, customFilter{field, function})
+// then custom filter will be used by calling customFilterFunc.
+// Passed joins are added only if the filter is used
+func (params *ListParams) AddCustomFilter(field string, function customFilterFunc, joins ...JoinClause) {
+	params.customFilters = append(params.customFilters,
+		customFilter{Field: field, Func: function, Joins: joinClausesToJoins(joins)})
+}
+
+// AddContextFilter adds custom filter called with context of params, see SetContext.
+// The function is called by Validate too, returned error makes the filter invalid.
+// Error can be RuleError, for example returned by Enum validator
+func (params *ListParams) AddContextFilter(field string, function contextFilterFunc, joins ...JoinClause) {
+	params.customFilters = append(params.customFilters,
+		customFilter{Field: field, ContextFunc: function, Joins: joinClausesToJoins(joins)})
 }
 
 // AddCustomIncludes adds custom includes. Custom includes overrides usual includes
@@ -207,14 +317,42 @@ func (params *ListParams) AddCustomIncludes(field string, function customInclude
 	params.Includes.AddCustomIncludes(field, function)
 }
 
-// AddCustomSortings adds custom sortings. Overrides usual sorting
-func (params *ListParams) AddCustomSortings(field string, function customSortingFunc) {
-	params.customSortings = append(params.customSortings, customSoting{field, function})
+// AddCustomSortings adds custom sortings. Overrides usual sorting.
+// Passed joins are added only if the sorting is used
+func (params *ListParams) AddCustomSortings(field string, function customSortingFunc, joins ...JoinClause) {
+	params.customSortings = append(params.customSortings,
+		customSoting{Field: field, Func: function, Joins: joinClausesToJoins(joins)})
+}
+
+// AddContextSortings adds custom sortings called with context of params, see SetContext.
+// The function is called by Validate too, returned error makes the sorting invalid
+func (params *ListParams) AddContextSortings(field string, function contextSortingFunc, joins ...JoinClause) {
+	params.customSortings = append(params.customSortings,
+		customSoting{Field: field, ContextFunc: function, Joins: joinClausesToJoins(joins)})
+}
+
+// AddContextIncludes adds custom includes called with context passed to LoadIncludes
+func (params *ListParams) AddContextIncludes(field string, function contextIncludesFunc) {
+	params.Includes.AddContextIncludes(field, function)
+}
+
+// SetContext sets context passed to context-aware custom filters and sortings
+func (params *ListParams) SetContext(ctx context.Context) {
+	params.ctx = ctx
+}
+
+// Context returns context of params. Returns background context if it is not set
+func (params *ListParams) Context() context.Context {
+	if params.ctx == nil {
+		return context.Background()
+	}
+	return params.ctx
 }
 
 // AllowSelectFields sets allowed list of fields. Than fields can be returned
 // depends on includes for Serializers
 func (params *ListParams) AllowSelectFields(fieldsSet []interface{}) {
+	params.Includes.modelType = params.ObjectType
 	params.Includes.AllowSelectFields(fieldsSet)
 }
 
@@ -239,15 +377,29 @@ func (params *ListParams) AllowIncludes(fields []string) {
 	params.Includes.Allow(fields)
 }
 
+// SetMaxIncludeDepth limits count of relations in include path, see Includes.SetMaxDepth
+func (params *ListParams) SetMaxIncludeDepth(depth int) {
+	params.Includes.SetMaxDepth(depth)
+}
+
+// VerifyIncludeRelations enables checking that allowed passed includes
+// are declared or can be resolved from struct fields of the model
+func (params *ListParams) VerifyIncludeRelations() {
+	params.verifyIncludes = true
+}
+
 // AllowSortings allows sorting. Needed to be valid
 func (params *ListParams) AllowSortings(fields []string) {
-	params.allowedListParams.Sortings = fields
+	params.allowedListParams.Sortings = append([]string(nil), fields...)
 }
 
+// SetGroupBy sets group by statement
 func (params *ListParams) SetGroupBy(groupBy string) {
 	params.groupBy = &groupBy
 }
 
+// GetGroupBy returns group by statement set by SetGroupBy.
+// Requested groups are not included, see GetAggregateGroupBy
 func (params *ListParams) GetGroupBy() *string {
 	return params.groupBy
 }
@@ -265,6 +417,20 @@ func (params *ListParams) GetOrderByString() string {
 	return strings.Join(orderByParts, ",")
 }
 
+// GetOrderByStringWithError returns SQL string for ORDER BY statement
+// or error of the first failed custom sorting
+func (params *ListParams) GetOrderByStringWithError() (string, error) {
+	orderByParts := make([]string, 0)
+	for _, sorting := range params.Sortings {
+		orderPart, err := params.getOrderByString(&sorting)
+		if err != nil {
+			return "", err
+		}
+		orderByParts = append(orderByParts, orderPart)
+	}
+	return strings.Join(orderByParts, ","), nil
+}
+
 // GetCustomIncludesFunctions calls GetCustomIncludesFunctions to Includes
 func (params *ListParams) GetCustomIncludesFunctions() []customIncludesFunc {
 	return params.Includes.GetCustomIncludesFunctions()
@@ -275,35 +441,103 @@ func (params *ListParams) GetPreloads() []string {
 	return params.Includes.GetPreloads()
 }
 
-// GetOutputFields calls GetOutputFields for Includes
+// GetOutputFields calls GetOutputFields for Includes.
+// Included counts of countable relations are added to root fields
 func (params *ListParams) GetOutputFields() []interface{} {
-	return params.Includes.GetOutputFields()
+	fields := params.Includes.GetOutputFields()
+	if len(params.Includes.fieldsSet) == 0 {
+		return fields
+	}
+	return append(fields, params.getCountOutputFields()...)
 }
 
-// GetJoinCondition returns SQL string with joins
+// GetJoinCondition returns SQL string with joins.
+// Joins of used custom filters and sortings and joins of relationships
+// used by filters and sortings are added automatically.
+// Use GetJoinConditionWithArgs if joins have arguments. Joins not supported by the dialect are not checked
 func (params *ListParams) GetJoinCondition() string {
-	joinParts := make([]string, len(params.joins))
-	for i, joinProps := range params.joins {
-		joinParts[i] = fmt.Sprintf("%s JOIN %s ON %s", joinProps.joinType, joinProps.tableName, joinProps.onStatement)
+	joins := params.getJoins()
+	joinParts := make([]string, len(joins))
+	for i, j := range joins {
+		joinParts[i] = j.sql()
 	}
 	return strings.Join(joinParts, " ")
 }
 
-// GetWhereCondition returns sql string with params for where statement
+// GetWhereCondition returns sql string with params for where statement.
+// Filters by fields of has-many relation are combined into one EXISTS subquery per relation.
+// Filters of included relations scopes are skipped, see IncludeParams.
+// If a context filter fails, returns condition matching no records. Use GetWhereConditionWithError to get the error
 func (params *ListParams) GetWhereCondition() (string, []interface{}) {
+	condition, arguments, err := params.GetWhereConditionWithError()
+	if err != nil {
+		return noRecordsCondition, []interface{}{}
+	}
+	return condition, arguments
+}
+
+// GetWhereConditionWithError returns sql string with params for where statement
+// or error of the first failed context filter.
+// Returns condition matching no records if an enforced condition has no values or renders to nothing
+func (params *ListParams) GetWhereConditionWithError() (string, []interface{}, error) {
+	condition, arguments, err := params.getFiltersCondition(params.Filters, true)
+	if err != nil {
+		return "", nil, err
+	}
+	enforced, err := params.getEnforcedFilters()
+	if err != nil {
+		return "", nil, err
+	}
+	if len(enforced) == 0 {
+		return condition, arguments, nil
+	}
+	for i := range enforced {
+		if len(enforced[i].Values) == 0 {
+			return noRecordsCondition, []interface{}{}, nil
+		}
+		part, _, err := params.getFiltersCondition(enforced[i:i+1], false)
+		if err != nil {
+			return "", nil, err
+		}
+		if part == "" {
+			return noRecordsCondition, []interface{}{}, nil
+		}
+	}
+	enforcedCondition, enforcedArguments, err := params.getFiltersCondition(enforced, false)
+	if err != nil {
+		return "", nil, err
+	}
+	if condition == "" {
+		return enforcedCondition, enforcedArguments, nil
+	}
+	return "(" + condition + ") AND (" + enforcedCondition + ")", append(arguments, enforcedArguments...), nil
+}
+
+// getFiltersCondition returns sql string with params for where statement by passed filters
+func (params *ListParams) getFiltersCondition(filters []FilterListParameter, skipScopes bool) (
+	string, []interface{}, error) {
 	filterStrs := make([]string, 0)
-	arguments := make([]interface{}, 0, len(params.Filters))
+	arguments := make([]interface{}, 0, len(filters))
+	existsRelations := make(map[string]bool)
 
-	for _, filter := range params.Filters {
+	for _, filter := range filters {
+		if skipScopes && params.isIncludeScopeFilter(&filter) {
+			continue
+		}
 		var conditionPart string
 		if custom := params.getCustomFilter(filter.Field); custom != nil {
 			//custom filter func may use multiple placeholders and return multiple arguments
 			//we have to check if it returned slice of arguments and append each the argument
 			//with unique index
 			//for example consider the following condition "(accounts.user_id = ? OR cards.user_id = ?)
-			conditionP, customFilterArgs := params.getConditionPartFromCustomFilter(custom, filter.Values)
+			conditionP, customFilterArgs, err := params.getConditionPartFromCustomFilter(custom, filter.Values)
+			if err != nil {
+				return "", nil, newFilterValueError(&filter, err)
+			}
 			conditionPart = conditionP
-			if reflect.TypeOf(customFilterArgs).Kind() == reflect.Slice {
+			if customFilterArgs == nil {
+				// condition without placeholders
+			} else if reflect.TypeOf(customFilterArgs).Kind() == reflect.Slice {
 				args := reflect.ValueOf(customFilterArgs)
 				for j := 0; j < args.Len(); j++ {
 					arguments = append(arguments, args.Index(j).Interface())
@@ -311,15 +545,33 @@ func (params *ListParams) GetWhereCondition() (string, []interface{}) {
 			} else {
 				arguments = append(arguments, customFilterArgs)
 			}
+		} else if relationName, ok := params.getCountRelationName(filter.Field); ok {
+			subquery, ok := params.getCountSubquery(relationName)
+			if !ok {
+				return "", nil, newCountRelationError(filterParameter(filter.Field, filter.Operator), filter.Field)
+			}
+			conditionP, args := params.getConditionPart(subquery, &filter)
+			conditionPart = conditionP
+			arguments = append(arguments, args)
+		} else if relationName, ok := params.getHasManyRelationName(filter.Field); ok {
+			if existsRelations[relationName] {
+				continue
+			}
+			existsRelations[relationName] = true
+			conditionP, args := params.getExistsCondition(relationName, filters, skipScopes)
+			conditionPart = conditionP
+			arguments = append(arguments, args...)
 		} else {
 			conditionP, args := params.GetConditionPartFromUsualFilter(&filter)
 			conditionPart = conditionP
 			arguments = append(arguments, args)
 		}
 
-		filterStrs = append(filterStrs, conditionPart)
+		if conditionPart != "" {
+			filterStrs = append(filterStrs, "("+conditionPart+")")
+		}
 	}
-	return strings.Join(filterStrs, " AND "), arguments
+	return strings.Join(filterStrs, " AND "), arguments, nil
 }
 
 // GetLimit returns limit can
@@ -327,30 +579,54 @@ func (params *ListParams) GetLimit() uint32 {
 	return params.Pagination.PageSize
 }
 
+// HasPageSize returns true if page size is passed in query.
+// Otherwise GetLimit returns default page size
+func (params *ListParams) HasPageSize() bool {
+	return len(params.rawQuery["page[size]"]) != 0
+}
+
 // GetOffset returns offset
 func (params *ListParams) GetOffset() uint32 {
 	return params.Pagination.PageSize * (params.Pagination.PageNumber - 1)
 }
 
-// GetConditionPartFromUsualFilter returns where condition string with params
+// GetConditionPartFromUsualFilter returns where condition string with params.
+// Values are converted to types of model fields, see GetFilterValues
 func (params *ListParams) GetConditionPartFromUsualFilter(filter *FilterListParameter) (string, interface{}) {
-	if operation, ok := operations[filter.Operator]; ok {
+	if _, ok := operations[filter.Operator]; ok {
 		transformName := params.transformName(filter.Field)
-		if len(strings.Split(transformName, sqlTableFieldDelimiter)) == 1 {
+		if column, _, ok := params.resolveRelationField(filter.Field); ok {
+			transformName = column
+		} else if len(strings.Split(transformName, sqlTableFieldDelimiter)) == 1 {
 			transformName = params.addTablePrefix(transformName)
 		}
-		return operation(transformName, filter.Values)
+		return params.getConditionPart(transformName, filter)
 	}
-	if len(filter.Values) == 1 {
+	values, err := params.GetFilterValues(filter)
+	if err != nil {
+		values = stringsToInterfaces(filter.Values)
+	}
+	if len(values) == 1 {
 		conditionStr := fmt.Sprintf("%s = ?", params.transformName(filter.Field))
-		return conditionStr, filter.Values[0]
+		return conditionStr, values[0]
 	}
 	conditionStr := fmt.Sprintf("%s IN (?)", params.transformName(filter.Field))
-	return conditionStr, filter.Values
+	return conditionStr, values
+}
+
+// getConditionPart returns condition for the expression by operator and values of the filter
+func (params *ListParams) getConditionPart(expression string, filter *FilterListParameter) (string, []interface{}) {
+	values, err := params.GetFilterValues(filter)
+	if err != nil {
+		values = stringsToInterfaces(filter.Values)
+	}
+	return operations[filter.Operator](expression, values)
 }
 
 // SelectFields receives list of fields in format as for AllowSelectFields method
-// Sets fields to select from db and returned by GetOutputFields method
+// Sets fields to select from db and returned by GetOutputFields method.
+// Fields requested by fields query params are selected by Validate and replace fields set before,
+// so SelectFields should be called after Validate to override them
 func (params *ListParams) SelectFields(fields []interface{}) {
 	params.Includes.SelectFields(fields)
 }
@@ -361,12 +637,7 @@ func (params *ListParams) GetSelectQuery() []string {
 
 // isJoinExist checks if given joinType exist in array of joins
 func (params *ListParams) isJoinExist(j join) bool {
-	for _, v := range params.joins {
-		if v.tableName == j.tableName && v.onStatement == j.onStatement && v.joinType == j.joinType {
-			return true
-		}
-	}
-	return false
+	return isJoinInList(params.joins, j)
 }
 
 // isDescDirection returns true if direction is DESC
@@ -377,10 +648,28 @@ func (sortingParams *SortingListParameter) isDescDirection() bool {
 // getOrderByString returns SQL string for ORDER BY
 func (params *ListParams) getOrderByString(sortingParam *SortingListParameter) (string, error) {
 	if custom := params.getCustomSorting(sortingParam.Field); custom != nil {
+		if custom.ContextFunc != nil {
+			return custom.ContextFunc(params.Context(), sortingParam.Direction, params)
+		}
 		return custom.Func(sortingParam.Direction, params)
 	}
+	if aggregate := params.getAggregateByAlias(sortingParam.Field); aggregate != nil {
+		return fmt.Sprintf("%s %s", aggregate.Alias, sortingParam.Direction), nil
+	}
+	if _, ok := params.getBucketGroup(sortingParam.Field); ok {
+		return fmt.Sprintf("%s %s", getGroupAlias(sortingParam.Field), sortingParam.Direction), nil
+	}
+	if relationName, ok := params.getCountRelationName(sortingParam.Field); ok {
+		subquery, ok := params.getCountSubquery(relationName)
+		if !ok {
+			return "", newCountRelationError("sort", sortingParam.Field)
+		}
+		return fmt.Sprintf("%s %s", subquery, sortingParam.Direction), nil
+	}
 	transformName := params.transformName(sortingParam.Field)
-	if len(strings.Split(transformName, sqlTableFieldDelimiter)) == 1 {
+	if column, _, ok := params.resolveRelationField(sortingParam.Field); ok {
+		transformName = column
+	} else if len(strings.Split(transformName, sqlTableFieldDelimiter)) == 1 {
 		transformName = params.addTablePrefix(transformName)
 	}
 
@@ -388,12 +677,16 @@ func (params *ListParams) getOrderByString(sortingParam *SortingListParameter) (
 }
 
 func newAllowedListParams() allowedListParams {
-	return allowedListParams{make([]string, 0), make([]FieldOperatorPair, 0), false}
+	return allowedListParams{make([]string, 0), make([]FieldOperatorPair, 0), false, make([]string, 0), make([]Aggregate, 0)}
 }
 
 func (params *ListParams) getConditionPartFromCustomFilter(
-	filter *customFilter, inputValues []string) (string, interface{}) {
-	return filter.Func(inputValues, params)
+	filter *customFilter, inputValues []string) (string, interface{}, error) {
+	if filter.ContextFunc != nil {
+		return filter.ContextFunc(params.Context(), inputValues, params)
+	}
+	condition, values := filter.Func(inputValues, params)
+	return condition, values, nil
 }
 
 func (params *ListParams) getCustomFilter(field string) *customFilter {
@@ -423,6 +716,9 @@ func (params *ListParams) setSortingParams(values url.Values) {
 	for _, sortingQueryParam := range sortingFields {
 		fields := strings.Split(sortingQueryParam, queryParamDelimiter)
 		for _, field := range fields {
+			if field == "" {
+				continue
+			}
 			sortingParameter := SortingListParameter{}
 			if (string)(field[0]) == "-" {
 				sortingParameter.Direction = DescDirection
@@ -439,15 +735,7 @@ func (params *ListParams) setSortingParams(values url.Values) {
 }
 
 func (params *ListParams) addTablePrefix(field string) string {
-	var tableName string
-	if method, ok := reflect.PtrTo(params.ObjectType).MethodByName(tableNameFuncName); ok {
-		result := method.Func.Call([]reflect.Value{reflect.New(params.ObjectType)})
-		tableName = result[0].Interface().(string)
-	} else {
-		pluralName := inflection.Plural(params.ObjectType.Name())
-		tableName = strcase.ToSnake(pluralName)
-
-	}
+	tableName := getTableName(params.ObjectType)
 	return strings.Join([]string{tableName, field}, sqlTableFieldDelimiter)
 }
 
@@ -511,28 +799,44 @@ func (params *ListParams) parseField(field string) (fieldName string, operator O
 }
 
 func (params *ListParams) addPaginationError() {
-	params.addError("Pagination is not allowed")
+	parameter := "page[size]"
+	if params.Pagination.PageNumber != DefaultPageNumber {
+		parameter = "page[number]"
+	}
+	params.addError(newParamError(CodePaginationNotAllowed, parameter, "", "", "", nil))
 }
 
 func (params *ListParams) addFilterError(field string, operator Operator) {
-	params.addError(fmt.Sprintf("Filter %s is not allowed with operator %s", field, operator))
+	code := CodeFilterNotAllowed
+	if params.isFilterOfAnyRole(field, operator) {
+		code = CodeFilterForbidden
+	}
+	params.addError(newParamError(code, filterParameter(field, operator), field, operator, "", nil))
+}
+
+func (params *ListParams) addFilterValueError(err *ParamError) {
+	params.addError(err)
 }
 
 func (params *ListParams) addIncludesError(field string) {
-	params.addError(fmt.Sprintf("Including of %s in not allowed", field))
+	params.addError(newIncludesError(field))
 }
 
 func (params *ListParams) addSortingError(field string) {
-	params.addError(fmt.Sprintf("Sorting by %s in not allowed", field))
+	code := CodeSortNotAllowed
+	if params.isSortingOfAnyRole(field) {
+		code = CodeSortForbidden
+	}
+	params.addError(newParamError(code, "sort", field, "", "", nil))
 }
 
-func (params *ListParams) addError(text string) {
-	params.errors = append(params.errors, NewErrorString(text))
+func (params *ListParams) addError(err *ParamError) {
+	params.errors = append(params.errors, err)
 }
 
 func (params *ListParams) addErrors(errors []error) {
 	for _, err := range errors {
-		params.errors = append(params.errors, NewErrorString(err.Error()))
+		params.addError(AsParamError(err))
 	}
 }
 
@@ -0,0 +1,229 @@
+package list_params
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultIncludesParallelism is count of custom includes loaded at the same time by default.
+// Custom includes are loaded one by one unless SetIncludesParallelism is called
+const DefaultIncludesParallelism = 1
+
+// Loader loads related records of custom include for all listed records in one batch
+type Loader struct {
+	// Keys returns keys of related records of the listed record
+	Keys func(record interface{}) []interface{}
+	// Fetch loads related records by unique keys of all listed records. Returns related records by keys
+	Fetch func(ctx context.Context, keys []interface{}) (map[interface{}]interface{}, error)
+	// Attach sets fetched related records to the listed record.
+	// Related records are in order of keys of the record, missing ones are skipped.
+	// Record is pointer to listed record
+	Attach func(record interface{}, related []interface{}) error
+}
+
+// IncludeError is error of loading of custom include
+type IncludeError struct {
+	Include string
+	Err     error
+}
+
+func (e *IncludeError) Error() string {
+	return "Including of " + e.Include + " failed: " + e.Err.Error()
+}
+
+func (e *IncludeError) Unwrap() error {
+	return e.Err
+}
+
+// IncludeErrors contains errors of all failed custom includes
+type IncludeErrors []*IncludeError
+
+func (e IncludeErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// AddIncludeLoader adds custom include loaded by loader. Overrides usual includes
+func (params *ListParams) AddIncludeLoader(field string, loader Loader) {
+	params.Includes.AddIncludeLoader(field, loader)
+}
+
+// SetIncludesParallelism sets count of custom includes loaded at the same time.
+// Functions and loaders of custom includes have to be safe for concurrent use if parallelism is greater than 1
+func (params *ListParams) SetIncludesParallelism(parallelism int) {
+	params.includesParallelism = parallelism
+}
+
+// LoadIncludes loads included custom includes and loaders for records. Pass pointer to slice of records.
+// Includes of the same depth are independent and can be loaded concurrently, see SetIncludesParallelism.
+// Nested includes are loaded after parents. Loading is stopped when ctx is done.
+// Returns IncludeErrors with errors of all failed includes, panics of includes are returned as errors
+func (params *ListParams) LoadIncludes(ctx context.Context, recordsPtr interface{}) error {
+	records, pointers := getRecords(recordsPtr)
+	for _, stage := range params.Includes.getCustomIncludesStages() {
+		if err := params.loadIncludesStage(ctx, stage, records, pointers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddIncludeLoader adds custom include loaded by loader
+func (self *Includes) AddIncludeLoader(field string, loader Loader) {
+	self.customIncludes = append(self.customIncludes, customIncludes{Field: field, Loader: &loader})
+}
+
+// getCustomIncludesStages returns included custom includes grouped by depth
+func (self *Includes) getCustomIncludesStages() [][]customIncludes {
+	stages := make(map[int][]customIncludes)
+	depths := make([]int, 0)
+	for _, v := range self.customIncludes {
+		if !self.isIncluded(v.Field) {
+			continue
+		}
+		depth := getIncludeDepth(v.Field)
+		if _, ok := stages[depth]; !ok {
+			depths = append(depths, depth)
+		}
+		stages[depth] = append(stages[depth], v)
+	}
+	sort.Ints(depths)
+
+	result := make([][]customIncludes, len(depths))
+	for i, depth := range depths {
+		result[i] = stages[depth]
+	}
+	return result
+}
+
+// loadIncludesStage loads independent includes one by one or concurrently if parallelism is greater than 1
+func (params *ListParams) loadIncludesStage(ctx context.Context, stage []customIncludes, records, pointers []interface{}) error {
+	parallelism := params.includesParallelism
+	if parallelism <= 0 {
+		parallelism = DefaultIncludesParallelism
+	}
+	errs := make([]*IncludeError, len(stage))
+	if parallelism == 1 {
+		for i, include := range stage {
+			if err := include.load(ctx, records, pointers); err != nil {
+				errs[i] = &IncludeError{include.Field, err}
+			}
+		}
+		return collectIncludeErrors(errs)
+	}
+
+	semaphore := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, include := range stage {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = &IncludeError{include.Field, ctx.Err()}
+			continue
+		}
+		wg.Add(1)
+		go func(i int, include customIncludes) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			if err := include.load(ctx, records, pointers); err != nil {
+				errs[i] = &IncludeError{include.Field, err}
+			}
+		}(i, include)
+	}
+	wg.Wait()
+	return collectIncludeErrors(errs)
+}
+
+// collectIncludeErrors returns IncludeErrors of failed includes or nil
+func collectIncludeErrors(errs []*IncludeError) error {
+	result := make(IncludeErrors, 0)
+	for _, err := range errs {
+		if err != nil {
+			result = append(result, err)
+		}
+	}
+	if len(result) != 0 {
+		return result
+	}
+	return nil
+}
+
+// load loads the include. Panic of the include is returned as error
+func (i customIncludes) load(ctx context.Context, records, pointers []interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if i.Loader != nil {
+		return i.Loader.load(ctx, pointers)
+	}
+	if i.ContextFunc != nil {
+		return i.ContextFunc(ctx, records)
+	}
+	return i.Func(records)
+}
+
+func (l *Loader) load(ctx context.Context, records []interface{}) error {
+	recordsKeys := make([][]interface{}, len(records))
+	keys := make([]interface{}, 0)
+	seen := make(map[interface{}]bool)
+	for i, record := range records {
+		recordsKeys[i] = l.Keys(record)
+		for _, key := range recordsKeys[i] {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	fetched := make(map[interface{}]interface{})
+	if len(keys) != 0 {
+		var err error
+		if fetched, err = l.Fetch(ctx, keys); err != nil {
+			return err
+		}
+	}
+
+	for i, record := range records {
+		related := make([]interface{}, 0, len(recordsKeys[i]))
+		for _, key := range recordsKeys[i] {
+			if value, ok := fetched[key]; ok {
+				related = append(related, value)
+			}
+		}
+		if err := l.Attach(record, related); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getRecords returns listed records and pointers to them from pointer to slice
+func getRecords(recordsPtr interface{}) (records []interface{}, pointers []interface{}) {
+	slice := reflect.Indirect(reflect.ValueOf(recordsPtr))
+	records = make([]interface{}, slice.Len())
+	pointers = make([]interface{}, slice.Len())
+	for i := 0; i < slice.Len(); i++ {
+		records[i] = slice.Index(i).Interface()
+		if slice.Index(i).Kind() == reflect.Ptr {
+			pointers[i] = records[i]
+		} else {
+			pointers[i] = slice.Index(i).Addr().Interface()
+		}
+	}
+	return records, pointers
+}
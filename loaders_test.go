@@ -0,0 +1,135 @@
+package list_params
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func newUserLoader(fetches *int) Loader {
+	return Loader{
+		Keys: func(record interface{}) []interface{} {
+			return []interface{}{record.(*card).UserID}
+		},
+		Fetch: func(ctx context.Context, keys []interface{}) (map[interface{}]interface{}, error) {
+			*fetches++
+			result := make(map[interface{}]interface{})
+			for _, key := range keys {
+				if key != uint(0) {
+					result[key] = &user{ID: key.(uint)}
+				}
+			}
+			return result, nil
+		},
+		Attach: func(record interface{}, related []interface{}) error {
+			if len(related) != 0 {
+				record.(*card).User = related[0].(*user)
+			}
+			return nil
+		},
+	}
+}
+
+func TestLoaderFetchesUniqueKeysInOneBatch(t *testing.T) {
+	params := NewListParamsFromQuery("include=user", card{})
+	params.AllowIncludes([]string{"user"})
+	fetches := 0
+	params.AddIncludeLoader("user", newUserLoader(&fetches))
+	cards := []card{{ID: 1, UserID: 3}, {ID: 2, UserID: 3}, {ID: 3}}
+
+	if err := params.LoadIncludes(context.Background(), &cards); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 1 {
+		t.Errorf("expected one fetch, got %d", fetches)
+	}
+	if cards[0].User == nil || cards[0].User != cards[1].User || cards[0].User.ID != 3 {
+		t.Errorf("expected the same user attached to both cards, got %v %v", cards[0].User, cards[1].User)
+	}
+	if cards[2].User != nil {
+		t.Errorf("expected missing user to be skipped, got %v", cards[2].User)
+	}
+}
+
+func TestLoaderIsNotCalledWithoutInclude(t *testing.T) {
+	params := NewListParamsFromQuery("", card{})
+	params.AllowIncludes([]string{"user"})
+	fetches := 0
+	params.AddIncludeLoader("user", newUserLoader(&fetches))
+	cards := []card{{ID: 1, UserID: 3}}
+
+	if err := params.LoadIncludes(context.Background(), &cards); err != nil || fetches != 0 {
+		t.Errorf("expected loader not to be called, got %d fetches and error %v", fetches, err)
+	}
+}
+
+func TestLoadIncludesCollectsErrorsAndPanics(t *testing.T) {
+	failure := errors.New("failure")
+	for _, parallelism := range []int{1, 3} {
+		params := NewListParamsFromQuery("include=user,secret,currency", card{})
+		params.AllowIncludes([]string{"user", "secret", "currency"})
+		params.SetIncludesParallelism(parallelism)
+		loaded := false
+		params.AddCustomIncludes("user", func([]interface{}) error { return failure })
+		params.AddCustomIncludes("secret", func([]interface{}) error { panic("secret") })
+		params.AddContextIncludes("currency", func(context.Context, []interface{}) error {
+			loaded = true
+			return nil
+		})
+
+		err := params.LoadIncludes(context.Background(), &[]card{{ID: 1}})
+		var includeErrors IncludeErrors
+		if !errors.As(err, &includeErrors) || len(includeErrors) != 2 {
+			t.Fatalf("parallelism %d: expected two include errors, got %v", parallelism, err)
+		}
+		if includeErrors[0].Include != "user" || !errors.Is(includeErrors[0], failure) ||
+			includeErrors[1].Include != "secret" {
+			t.Errorf("parallelism %d: unexpected errors %v", parallelism, includeErrors)
+		}
+		if !loaded {
+			t.Errorf("parallelism %d: expected other includes to be loaded", parallelism)
+		}
+	}
+}
+
+func TestNestedIncludesAreLoadedAfterParents(t *testing.T) {
+	params := NewListParamsFromQuery("include=user.accounts,user", card{})
+	params.AllowIncludes([]string{"user", "user.accounts"})
+	params.SetIncludesParallelism(2)
+	var mutex sync.Mutex
+	order := make([]string, 0)
+	load := func(include string) customIncludesFunc {
+		return func([]interface{}) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			order = append(order, include)
+			return nil
+		}
+	}
+	params.AddCustomIncludes("user.accounts", load("user.accounts"))
+	params.AddCustomIncludes("user", load("user"))
+
+	if err := params.LoadIncludes(context.Background(), &[]card{{ID: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(order, []string{"user", "user.accounts"}) {
+		t.Errorf("expected parent to be loaded first, got %v", order)
+	}
+}
+
+func TestLoadIncludesStopsWhenContextIsDone(t *testing.T) {
+	params := NewListParamsFromQuery("include=user", card{})
+	params.AllowIncludes([]string{"user"})
+	fetches := 0
+	params.AddIncludeLoader("user", newUserLoader(&fetches))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := params.LoadIncludes(ctx, &[]card{{ID: 1, UserID: 3}})
+	var includeErrors IncludeErrors
+	if !errors.As(err, &includeErrors) || !errors.Is(includeErrors[0], context.Canceled) || fetches != 0 {
+		t.Errorf("expected canceled error without fetches, got %v and %d fetches", err, fetches)
+	}
+}
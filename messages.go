@@ -0,0 +1,270 @@
+package list_params
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale is used when no messages are registered for requested locale
+const DefaultLocale = "en"
+
+const (
+	ArgField    = "field"
+	ArgOperator = "operator"
+	ArgValue    = "value"
+)
+
+// MessageFilterValueRejected is key of message template of CodeFilterValueInvalid errors
+// returned by custom validators. Templates can use {reason} argument
+const MessageFilterValueRejected = ErrorCode("filter_value_invalid.rejected")
+
+// Messages are message templates by error codes.
+// Templates may contain placeholders like {field}, {operator} and {value}
+// and arguments of the error, for example {allowed} or {min}
+type Messages map[ErrorCode]string
+
+// Catalog provides message templates for validation errors
+type Catalog interface {
+	// Template returns message template for the code in given locale
+	Template(locale string, code ErrorCode) (string, bool)
+	// MatchLocale returns supported locale by Accept-Language header
+	MatchLocale(acceptLanguage string) string
+}
+
+// MessageCatalog is Catalog keeping messages in memory
+type MessageCatalog struct {
+	mutex    sync.RWMutex
+	messages map[string]Messages
+}
+
+// DefaultCatalog is used by ListParams unless another catalog is set
+var DefaultCatalog = NewMessageCatalog()
+
+var englishMessages = Messages{
+	CodeInvalidQuery:            "Query format is invalid",
+	CodeFilterNotAllowed:        "Filter {field} is not allowed with operator {operator}",
+	CodeSortNotAllowed:          "Sorting by {field} in not allowed",
+	CodeIncludeNotAllowed:       "Including of {field} in not allowed",
+	CodePaginationNotAllowed:    "Pagination is not allowed",
+	CodeFilterValueInvalid:      "Filter {field} has invalid value {value}: must be a valid {type}",
+	CodeFilterValueNotInEnum:    "Filter {field} has invalid value {value}: must be one of {allowed}",
+	CodeFilterValuePattern:      "Filter {field} has invalid value {value}: must match {pattern}",
+	CodeFilterValueTooSmall:     "Filter {field} has invalid value {value}: must be a number not less than {min}",
+	CodeFilterValueTooLarge:     "Filter {field} has invalid value {value}: must be a number not greater than {max}",
+	CodeFilterValueTooLong:      "Filter {field} has invalid value {value}: must be at most {max_length} characters long",
+	CodeFilterTooManyValues:     "Filter {field} has invalid value {value}: must contain at most {max_values} values",
+	MessageFilterValueRejected:  "Filter {field} has invalid value {value}: {reason}",
+	CodeQuantifierInvalid:       "Quantifier {value} of {field} is invalid: must be one of some, none, every",
+	CodeIncludeParentNotAllowed: "Including of {field} requires including of {parent} which is not allowed",
+	CodeIncludeTooDeep:          "Including of {field} is too deep: must be at most {max_depth} levels",
+	CodeIncludeRelationNotFound: "Including of {field} is invalid: relation does not exist",
+	CodeSortRejected:            "Sorting by {field} is invalid: {reason}",
+	CodeFilterEnforced:          "Filter {field} can not be changed",
+	CodeFilterForbidden:         "Filter {field} with operator {operator} is forbidden",
+	CodeSortForbidden:           "Sorting by {field} is forbidden",
+	CodeIncludeForbidden:        "Including of {field} is forbidden",
+	CodeSortMasked:              "Sorting by masked field {field} is not allowed",
+	CodeFilterMasked:            "Filter by masked field {field} is not allowed",
+	CodeFieldNotAllowed:         "Field {field} is not allowed",
+	CodeGroupNotAllowed:         "Grouping by {field} is not allowed",
+	CodeAggregateNotAllowed:     "Aggregate {field} is not allowed",
+	CodeAggregateInvalid:        "Aggregate {value} is invalid: must be in format function(field)",
+	CodeHavingNotAllowed:        "Having filter {field} is not allowed: must be alias of requested aggregate",
+	CodeGroupBucketInvalid:      "Grouping {value} is invalid: {field} must be time field grouped by hour, day, week, month or year",
+	CodeTimezoneInvalid:         "Timezone {value} is invalid: must be IANA time zone name",
+	CodeCountNotAvailable:       "Count {field} is not available: relation must be has-one, has-many or many-to-many",
+}
+
+// NewMessageCatalog returns catalog with default english messages
+func NewMessageCatalog() *MessageCatalog {
+	catalog := &MessageCatalog{messages: make(map[string]Messages)}
+	catalog.Register(DefaultLocale, englishMessages)
+	return catalog
+}
+
+// RegisterMessages registers translations in DefaultCatalog
+func RegisterMessages(locale string, messages Messages) {
+	DefaultCatalog.Register(locale, messages)
+}
+
+// Register adds messages for the locale. Already registered messages are overridden
+func (c *MessageCatalog) Register(locale string, messages Messages) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	locale = normalizeLocale(locale)
+	if _, ok := c.messages[locale]; !ok {
+		c.messages[locale] = make(Messages)
+	}
+	for code, template := range messages {
+		c.messages[locale][code] = template
+	}
+}
+
+// Template returns template for the locale. Falls back to the base language
+// of the locale and then to DefaultLocale
+func (c *MessageCatalog) Template(locale string, code ErrorCode) (string, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	for _, candidate := range localeCandidates(locale) {
+		if template, ok := c.messages[candidate][code]; ok {
+			return template, true
+		}
+	}
+	return "", false
+}
+
+// HasLocale returns true if messages are registered for the locale or its base language
+func (c *MessageCatalog) HasLocale(locale string) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	locale = normalizeLocale(locale)
+	if _, ok := c.messages[locale]; ok {
+		return true
+	}
+	_, ok := c.messages[baseLanguage(locale)]
+	return ok
+}
+
+// MatchLocale returns the most preferred locale from Accept-Language header
+// which has registered messages. Returns DefaultLocale if nothing matches
+func (c *MessageCatalog) MatchLocale(acceptLanguage string) string {
+	for _, locale := range parseAcceptLanguage(acceptLanguage) {
+		if c.HasLocale(locale) {
+			return locale
+		}
+	}
+	return DefaultLocale
+}
+
+// LocaleFromRequest returns locale matched by Accept-Language header of the request in DefaultCatalog
+func LocaleFromRequest(r *http.Request) string {
+	return DefaultCatalog.MatchLocale(r.Header.Get("Accept-Language"))
+}
+
+// LocalizeErrors returns copies of the errors with messages in given locale.
+// Errors which are not ParamError are returned as is
+func LocalizeErrors(catalog Catalog, locale string, errs []error) []error {
+	result := make([]error, len(errs))
+	for i, err := range errs {
+		result[i] = err
+		if paramErr, ok := err.(*ParamError); ok {
+			localized := *paramErr
+			localized.Detail = formatMessage(catalog, locale, paramErr)
+			result[i] = &localized
+		}
+	}
+	return result
+}
+
+// SetCatalog sets catalog used by ValidateWithLocale
+func (params *ListParams) SetCatalog(catalog Catalog) {
+	params.catalog = catalog
+}
+
+// ValidateWithLocale works as Validate but returns errors with messages in given locale
+func (params *ListParams) ValidateWithLocale(locale string) (bool, []error) {
+	ok, errs := params.Validate()
+	return ok, LocalizeErrors(params.catalog, locale, errs)
+}
+
+// ValidateRequest works as Validate but returns errors with messages
+// in locale taken from Accept-Language header of the request
+func (params *ListParams) ValidateRequest(r *http.Request) (bool, []error) {
+	params.SetContext(r.Context())
+	return params.ValidateWithLocale(params.catalog.MatchLocale(r.Header.Get("Accept-Language")))
+}
+
+// formatMessage renders template of the error code with arguments of the error
+func formatMessage(catalog Catalog, locale string, err *ParamError) string {
+	key := err.Code
+	if _, ok := err.Args["reason"]; ok && key == CodeFilterValueInvalid {
+		key = MessageFilterValueRejected
+	}
+	template, ok := catalog.Template(locale, key)
+	if !ok {
+		if template, ok = DefaultCatalog.Template(DefaultLocale, key); !ok {
+			return err.Detail
+		}
+	}
+	replacements := []string{
+		"{" + ArgField + "}", err.Field,
+		"{" + ArgOperator + "}", string(err.Operator),
+		"{" + ArgValue + "}", err.Value,
+	}
+	for name, value := range err.Args {
+		replacements = append(replacements, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(replacements...).Replace(template)
+}
+
+// newParamError creates ParamError with message in DefaultLocale
+func newParamError(code ErrorCode, parameter, field string, operator Operator, value string,
+	args map[string]string) *ParamError {
+	err := &ParamError{
+		Code:      code,
+		Parameter: parameter,
+		Field:     field,
+		Operator:  operator,
+		Value:     value,
+		Args:      args,
+	}
+	err.Detail = formatMessage(DefaultCatalog, DefaultLocale, err)
+	return err
+}
+
+// parseAcceptLanguage returns locales from Accept-Language header ordered by quality
+func parseAcceptLanguage(header string) []string {
+	type weightedLocale struct {
+		locale  string
+		quality float64
+	}
+	weighted := make([]weightedLocale, 0)
+	for _, part := range strings.Split(header, ",") {
+		pieces := strings.Split(strings.TrimSpace(part), ";")
+		locale := strings.TrimSpace(pieces[0])
+		if locale == "" || locale == "*" {
+			continue
+		}
+		quality := 1.0
+		for _, piece := range pieces[1:] {
+			piece = strings.TrimSpace(piece)
+			if strings.HasPrefix(piece, "q=") {
+				if q, err := strconv.ParseFloat(piece[2:], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			weighted = append(weighted, weightedLocale{locale, quality})
+		}
+	}
+	sort.SliceStable(weighted, func(i, j int) bool {
+		return weighted[i].quality > weighted[j].quality
+	})
+
+	result := make([]string, len(weighted))
+	for i, v := range weighted {
+		result[i] = normalizeLocale(v.locale)
+	}
+	return result
+}
+
+func localeCandidates(locale string) []string {
+	locale = normalizeLocale(locale)
+	candidates := []string{locale}
+	if base := baseLanguage(locale); base != locale {
+		candidates = append(candidates, base)
+	}
+	return append(candidates, DefaultLocale)
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.Replace(locale, "_", "-", -1))
+}
+
+func baseLanguage(locale string) string {
+	return strings.Split(locale, "-")[0]
+}
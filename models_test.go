@@ -0,0 +1,39 @@
+package list_params
+
+import "time"
+
+// Models shared by tests of list params features
+
+type user struct {
+	ID    uint   `json:"id"`
+	Email string `json:"email"`
+}
+
+type card struct {
+	ID         uint      `json:"id"`
+	Amount     int64     `json:"amount"`
+	Status     string    `json:"status"`
+	CardNumber string    `json:"pan"`
+	Secret     string    `json:"secret"`
+	Currency   string    `json:"currency"`
+	UserID     uint      `json:"userId"`
+	User       *user     `json:"user"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type transaction struct {
+	ID        uint      `json:"id"`
+	AccountID uint      `json:"accountId"`
+	Status    string    `json:"status"`
+	Amount    int64     `json:"amount"`
+	Fee       float64   `json:"fee" gorm:"type:decimal(10,2)"`
+	Currency  string    `json:"currency"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type account struct {
+	ID           uint          `json:"id"`
+	UserID       uint          `json:"userId"`
+	User         *user         `json:"user"`
+	Transactions []transaction `json:"transactions" gorm:"foreignkey:AccountID"`
+}
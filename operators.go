@@ -1,6 +1,7 @@
 package list_params
 
 import (
+	"fmt"
 	"strings"
 )
 
@@ -20,47 +21,39 @@ const (
 
 const operatorDelimiter = ":"
 
-type operation func(field string, values []string) (string, []interface{})
+type operation func(field string, values []interface{}) (string, []interface{})
 
 var (
 	operations = map[Operator]operation{
-		OperatorEq: func(field string, values []string) (string, []interface{}) {
+		OperatorEq: func(field string, values []interface{}) (string, []interface{}) {
 			return expressionTemplate(field, "OR", "=", values)
 		},
-		OperatorNeq: func(field string, values []string) (string, []interface{}) {
+		OperatorNeq: func(field string, values []interface{}) (string, []interface{}) {
 			return expressionTemplate(field, "OR", "!=", values)
 		},
-		OperatorLt: func(field string, values []string) (string, []interface{}) {
+		OperatorLt: func(field string, values []interface{}) (string, []interface{}) {
 			return expressionTemplate(field, "AND", "<", values)
 		},
-		OperatorGt: func(field string, values []string) (string, []interface{}) {
+		OperatorGt: func(field string, values []interface{}) (string, []interface{}) {
 			return expressionTemplate(field, "AND", ">", values)
 		},
-		OperatorLte: func(field string, values []string) (string, []interface{}) {
+		OperatorLte: func(field string, values []interface{}) (string, []interface{}) {
 			return expressionTemplate(field, "AND", "<=", values)
 		},
-		OperatorGte: func(field string, values []string) (string, []interface{}) {
+		OperatorGte: func(field string, values []interface{}) (string, []interface{}) {
 			return expressionTemplate(field, "AND", ">=", values)
 		},
-		OperatorIn: func(field string, values []string) (string, []interface{}) {
-			args := make([]interface{}, len(values))
-			for i, v := range values {
-				args[i] = v
-			}
-			return field + " IN (?)", args
+		OperatorIn: func(field string, values []interface{}) (string, []interface{}) {
+			return field + " IN (?)", values
 		},
-		OperatorNin: func(field string, values []string) (string, []interface{}) {
-			args := make([]interface{}, len(values))
-			for i, v := range values {
-				args[i] = v
-			}
-			return field + " NOT IN (?)", args
+		OperatorNin: func(field string, values []interface{}) (string, []interface{}) {
+			return field + " NOT IN (?)", values
 		},
-		OperatorLike: func(field string, values []string) (string, []interface{}) {
+		OperatorLike: func(field string, values []interface{}) (string, []interface{}) {
 			if len(values) == 0 {
 				return "", []interface{}{}
 			}
-			return field + " LIKE ?", []interface{}{"%" + values[0] + "%"}
+			return field + " LIKE ?", []interface{}{fmt.Sprintf("%%%v%%", values[0])}
 		},
 	}
 	knownOperators = map[string]Operator{
@@ -76,7 +69,7 @@ var (
 	}
 )
 
-func expressionTemplate(field, connector, operator string, values []string) (string, []interface{}) {
+func expressionTemplate(field, connector, operator string, values []interface{}) (string, []interface{}) {
 	if len(values) == 0 {
 		return "", nil
 	}
@@ -90,10 +83,6 @@ func expressionTemplate(field, connector, operator string, values []string) (str
 	}
 
 	res := strings.Join(templates, " "+connector+" ")
-	args := make([]interface{}, len(values))
-	for i, v := range values {
-		args[i] = v
-	}
 
-	return res, args
+	return res, values
 }
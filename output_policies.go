@@ -0,0 +1,203 @@
+package list_params
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+// OutputPolicy defines how value of the field is output
+type OutputPolicy string
+
+const (
+	PolicyHide = OutputPolicy("hide") // Field is removed from output
+	PolicyMask = OutputPolicy("mask") // All characters except last 4 are replaced by *. Short values are masked completely
+	PolicyHash = OutputPolicy("hash") // Value is replaced by hex encoded SHA-256 hash
+)
+
+const maskChar = "*"
+const maskVisibleChars = 4
+
+// PolicyField is entry of AllowSelectFields with output policy
+type PolicyField struct {
+	Name   string
+	Policy OutputPolicy
+}
+
+// WithPolicy returns entry of AllowSelectFields output by the policy.
+// Example: AllowSelectFields([]interface{}{"ID", WithPolicy("Number", PolicyMask)})
+func WithPolicy(field string, policy OutputPolicy) PolicyField {
+	return PolicyField{Name: field, Policy: policy}
+}
+
+// ProjectMap returns record with output fields only and output policies applied.
+// Keys of the record are names of fields as in AllowSelectFields, nested records are maps or slices of maps
+func (params *ListParams) ProjectMap(record map[string]interface{}) map[string]interface{} {
+	return params.Includes.projectMap(record, params.GetOutputFields(), "")
+}
+
+// isMaskedField returns true if the sorted or filtered field refers to field with output policy
+// by json name or by name. Masked fields can not be used for sorting and filtering with any operator
+func (params *ListParams) isMaskedField(field string) bool {
+	if params.Includes.isMaskedField(field) {
+		return true
+	}
+	path, ok := params.getStructFieldPath(field)
+	return ok && params.Includes.isMaskedField(path)
+}
+
+// isMaskedField returns true if the field path of AllowSelectFields has output policy
+func (self *Includes) isMaskedField(field string) bool {
+	_, ok := self.policies[normalizeFieldPath(field)]
+	return ok
+}
+
+func (self *Includes) projectMap(record map[string]interface{}, fields []interface{}, path string) map[string]interface{} {
+	result := make(map[string]interface{})
+	for _, field := range fields {
+		if name, ok := field.(string); ok {
+			value, ok := record[name]
+			if !ok {
+				continue
+			}
+			policy, hasPolicy := self.policies[normalizeFieldPath(joinFieldPath(path, name))]
+			if !hasPolicy {
+				result[name] = value
+			} else if policy != PolicyHide {
+				result[name] = applyOutputPolicy(policy, value)
+			}
+			continue
+		}
+		for name, nestedFields := range field.(map[string][]interface{}) {
+			if value, ok := record[name]; ok {
+				result[name] = self.projectNested(value, nestedFields, joinFieldPath(path, name))
+			}
+		}
+	}
+	return result
+}
+
+func (self *Includes) projectNested(value interface{}, fields []interface{}, path string) interface{} {
+	switch nested := value.(type) {
+	case map[string]interface{}:
+		return self.projectMap(nested, fields, path)
+	case []map[string]interface{}:
+		result := make([]interface{}, len(nested))
+		for i, v := range nested {
+			result[i] = self.projectMap(v, fields, path)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(nested))
+		for i, v := range nested {
+			result[i] = self.projectNested(v, fields, path)
+		}
+		return result
+	}
+	return value
+}
+
+// setOutputPolicies replaces entries with policies by field names and keeps policies by field paths
+func (self *Includes) setOutputPolicies(fields []interface{}, path string) []interface{} {
+	result := make([]interface{}, len(fields))
+	for i, field := range fields {
+		switch v := field.(type) {
+		case PolicyField:
+			self.policies[normalizeFieldPath(joinFieldPath(path, v.Name))] = v.Policy
+			result[i] = v.Name
+		case map[string][]interface{}:
+			nested := make(map[string][]interface{}, len(v))
+			for key, values := range v {
+				nested[key] = self.setOutputPolicies(values, joinFieldPath(path, key))
+			}
+			result[i] = nested
+		default:
+			result[i] = field
+		}
+	}
+	return result
+}
+
+// mergeOutputPolicies keeps policies of fields which are not allowed without policies by other fields
+func mergeOutputPolicies(policies map[string]OutputPolicy, fields []interface{},
+	otherPolicies map[string]OutputPolicy, otherFields []interface{}) map[string]OutputPolicy {
+	result := make(map[string]OutputPolicy)
+	otherPaths := selectFieldPaths(otherFields, "")
+	for path, policy := range policies {
+		if _, ok := otherPolicies[path]; ok || !otherPaths[path] {
+			result[path] = policy
+		}
+	}
+	paths := selectFieldPaths(fields, "")
+	for path, policy := range otherPolicies {
+		if _, ok := policies[path]; ok || !paths[path] {
+			if _, ok := result[path]; !ok {
+				result[path] = policy
+			}
+		}
+	}
+	return result
+}
+
+// selectFieldPaths returns normalized paths of fields in format of AllowSelectFields
+func selectFieldPaths(fields []interface{}, path string) map[string]bool {
+	result := make(map[string]bool)
+	for _, field := range fields {
+		if reflect.ValueOf(field).Kind() == reflect.String {
+			result[normalizeFieldPath(joinFieldPath(path, field.(string)))] = true
+			continue
+		}
+		for key, values := range field.(map[string][]interface{}) {
+			for nested := range selectFieldPaths(values, joinFieldPath(path, key)) {
+				result[nested] = true
+			}
+		}
+	}
+	return result
+}
+
+func applyOutputPolicy(policy OutputPolicy, value interface{}) interface{} {
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	str := fmt.Sprint(v.Interface())
+	switch policy {
+	case PolicyMask:
+		runes := []rune(str)
+		if len(runes) <= maskVisibleChars {
+			return strings.Repeat(maskChar, len(runes))
+		}
+		return strings.Repeat(maskChar, len(runes)-maskVisibleChars) + string(runes[len(runes)-maskVisibleChars:])
+	case PolicyHash:
+		hash := sha256.Sum256([]byte(str))
+		return hex.EncodeToString(hash[:])
+	}
+	return value
+}
+
+func joinFieldPath(path string, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + includesSeparator + field
+}
+
+// normalizeFieldPath returns snake case path to compare names of fields, columns and json keys
+func normalizeFieldPath(path string) string {
+	parts := strings.Split(path, includesSeparator)
+	for i, part := range parts {
+		parts[i] = strcase.ToSnake(part)
+	}
+	return strings.Join(parts, includesSeparator)
+}
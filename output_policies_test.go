@@ -0,0 +1,81 @@
+package list_params
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMaskedFieldCanNotBeSortedOrFiltered(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		code  ErrorCode
+	}{
+		{"filter by json name", "filter[pan:like]=1234", CodeFilterMasked},
+		{"filter by field name", "filter[card_number]=1234", CodeFilterMasked},
+		{"sort by json name", "sort=pan", CodeSortMasked},
+		{"sort by json name descending", "sort=-pan", CodeSortMasked},
+		{"filter by nested field", "filter[user.email]=a@b.c", CodeFilterMasked},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			params := NewListParamsFromQuery(test.query, card{})
+			params.AllowSelectFields([]interface{}{"ID", WithPolicy("CardNumber", PolicyMask),
+				map[string][]interface{}{"User": {WithPolicy("Email", PolicyHash)}}})
+			params.AllowFilters([]string{"pan:like", "card_number", "user.email"})
+			params.AllowSortings([]string{"pan"})
+
+			ok, errs := params.Validate()
+			if ok || len(errs) != 1 || AsParamError(errs[0]).Code != test.code {
+				t.Errorf("expected %s error, got %v", test.code, errs)
+			}
+		})
+	}
+}
+
+func TestFieldWithoutPolicyCanBeFiltered(t *testing.T) {
+	params := NewListParamsFromQuery("filter[status]=active&sort=id", card{})
+	params.AllowSelectFields([]interface{}{"ID", "Status", WithPolicy("CardNumber", PolicyMask)})
+	params.AllowFilters([]string{"status"})
+	params.AllowSortings([]string{"id"})
+
+	if ok, errs := params.Validate(); !ok {
+		t.Errorf("expected valid params, got %v", errs)
+	}
+}
+
+func TestProjectMapAppliesPolicies(t *testing.T) {
+	params := NewListParamsFromQuery("", card{})
+	params.AllowSelectFields([]interface{}{"ID", WithPolicy("CardNumber", PolicyMask),
+		WithPolicy("Secret", PolicyHide), WithPolicy("Currency", PolicyHash)})
+
+	result := params.ProjectMap(map[string]interface{}{
+		"ID": 1, "CardNumber": "4111111111111111", "Secret": "s", "Currency": "EUR", "Status": "active"})
+	expected := map[string]interface{}{
+		"ID":         1,
+		"CardNumber": "************1111",
+		"Currency":   "57d4846cecee3fddcb443137723fd1b46d56e64331634ef3c922b72e57f3388e",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestApplyOutputPolicy(t *testing.T) {
+	tests := []struct {
+		policy   OutputPolicy
+		value    interface{}
+		expected interface{}
+	}{
+		{PolicyMask, "1234", "****"},
+		{PolicyMask, "12345", "*2345"},
+		{PolicyMask, 1234567, "***4567"},
+		{PolicyMask, (*string)(nil), nil},
+		{PolicyHash, "", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+	}
+	for _, test := range tests {
+		if result := applyOutputPolicy(test.policy, test.value); result != test.expected {
+			t.Errorf("%s of %v: expected %v, got %v", test.policy, test.value, test.expected, result)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package list_params
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Quantifier defines how filters by fields of has-many relation are matched
+type Quantifier string
+
+const (
+	QuantifierSome  = Quantifier("some")  // At least one related record matches all filters
+	QuantifierNone  = Quantifier("none")  // No related record matches all filters
+	QuantifierEvery = Quantifier("every") // All related records match all filters
+)
+
+var knownQuantifiers = map[Quantifier]bool{
+	QuantifierSome:  true,
+	QuantifierNone:  true,
+	QuantifierEvery: true,
+}
+
+// SetQuantifier sets quantifier of filters by fields of has-many relation.
+// QuantifierSome is used by default
+func (params *ListParams) SetQuantifier(relation string, quantifier Quantifier) {
+	params.quantifiers[relation] = quantifier
+}
+
+// getQuantifier returns quantifier of has-many relation
+func (params *ListParams) getQuantifier(relation string) Quantifier {
+	if quantifier, ok := params.quantifiers[relation]; ok {
+		return quantifier
+	}
+	return QuantifierSome
+}
+
+// setQuantifiers takes quantifiers of relations from quantifier[relation] params
+func (params *ListParams) setQuantifiers(values url.Values) {
+	pattern := regexp.MustCompile(`^quantifier\[(.*)\]$`)
+	for k, v := range values {
+		submatches := pattern.FindStringSubmatch(k)
+		if len(submatches) < 2 || len(v) == 0 {
+			continue
+		}
+		params.quantifiers[submatches[1]] = Quantifier(v[0])
+	}
+}
+
+func (params *ListParams) validateQuantifiers() {
+	for relation, quantifier := range params.quantifiers {
+		if !knownQuantifiers[quantifier] {
+			params.addError(newParamError(CodeQuantifierInvalid, fmt.Sprintf("quantifier[%s]", relation),
+				relation, "", string(quantifier), nil))
+		}
+	}
+}
+
+// getHasManyRelationName returns name of has-many relation
+// if the field is in format [has-many relationship].[field]
+func (params *ListParams) getHasManyRelationName(field string) (string, bool) {
+	parts := strings.Split(field, sqlTableFieldDelimiter)
+	if len(parts) != 2 || params.ObjectType == nil {
+		return "", false
+	}
+	if _, isCount := params.getCountRelationName(field); isCount {
+		return "", false
+	}
+	relation, ok := params.getRelation(indirectType(params.ObjectType), parts[0])
+	if !ok || relation.Kind != RelationHasMany {
+		return "", false
+	}
+	return parts[0], true
+}
+
+// getExistsCondition returns EXISTS subquery for all usual filters by fields of has-many relation.
+// Each filter condition adds one argument as usual filters do
+func (params *ListParams) getExistsCondition(relationName string, filters []FilterListParameter, skipScopes bool) (
+	string, []interface{}) {
+	relation, _ := params.getRelation(indirectType(params.ObjectType), relationName)
+	parentTable := getTableName(indirectType(params.ObjectType))
+	alias := params.relationAlias(relationName)
+
+	conditions := make([]string, 0)
+	arguments := make([]interface{}, 0)
+	for _, filter := range filters {
+		if params.getCustomFilter(filter.Field) != nil || (skipScopes && params.isIncludeScopeFilter(&filter)) {
+			continue
+		}
+		if name, ok := params.getHasManyRelationName(filter.Field); !ok || name != relationName {
+			continue
+		}
+		if _, ok := operations[filter.Operator]; !ok {
+			continue
+		}
+		fieldName := strings.Split(filter.Field, sqlTableFieldDelimiter)[1]
+		column := strings.Join([]string{alias, getColumnName(relation.modelType, fieldName)}, sqlTableFieldDelimiter)
+		condition, args := params.getConditionPart(column, &filter)
+		conditions = append(conditions, "("+condition+")")
+		arguments = append(arguments, args)
+	}
+
+	matching := strings.Join(conditions, " AND ")
+	quantifier := params.getQuantifier(relationName)
+	if quantifier == QuantifierEvery {
+		matching = fmt.Sprintf("NOT (%s)", matching)
+	}
+	subquery := fmt.Sprintf("SELECT 1 FROM %s AS %s WHERE %s AND %s",
+		relation.Table, alias, relation.onStatement(parentTable, alias), matching)
+	if quantifier == QuantifierSome {
+		return fmt.Sprintf("EXISTS (%s)", subquery), arguments
+	}
+	return fmt.Sprintf("NOT EXISTS (%s)", subquery), arguments
+}
+
+func stringsToInterfaces(values []string) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
@@ -0,0 +1,80 @@
+package list_params
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRelationFiltersConditions(t *testing.T) {
+	const subquery = "SELECT 1 FROM transactions AS rel_transactions " +
+		"WHERE rel_transactions.account_id = accounts.id AND "
+	tests := []struct {
+		name       string
+		quantifier Quantifier
+		filters    []FilterListParameter
+		where      string
+		arguments  string
+	}{
+		{"some", QuantifierSome, []FilterListParameter{
+			{FieldOperatorPair{"transactions.status", OperatorEq}, []string{"failed"}},
+		}, "(EXISTS (" + subquery + "(rel_transactions.status = ?)))", "[[failed]]"},
+		{"some by two fields", QuantifierSome, []FilterListParameter{
+			{FieldOperatorPair{"transactions.status", OperatorEq}, []string{"failed"}},
+			{FieldOperatorPair{"transactions.amount", OperatorGt}, []string{"100"}},
+		}, "(EXISTS (" + subquery + "(rel_transactions.status = ?) AND (rel_transactions.amount > ?)))",
+			"[[failed] [100]]"},
+		{"every", QuantifierEvery, []FilterListParameter{
+			{FieldOperatorPair{"transactions.status", OperatorEq}, []string{"done"}},
+		}, "(NOT EXISTS (" + subquery + "NOT ((rel_transactions.status = ?))))", "[[done]]"},
+		{"none", QuantifierNone, []FilterListParameter{
+			{FieldOperatorPair{"transactions.status", OperatorIn}, []string{"failed", "canceled"}},
+		}, "(NOT EXISTS (" + subquery + "(rel_transactions.status IN (?))))", "[[failed canceled]]"},
+		{"some with filters of the model", QuantifierSome, []FilterListParameter{
+			{FieldOperatorPair{"user_id", OperatorEq}, []string{"3"}},
+			{FieldOperatorPair{"transactions.status", OperatorEq}, []string{"failed"}},
+			{FieldOperatorPair{"id", OperatorGt}, []string{"10"}},
+		}, "(accounts.user_id = ?) AND (EXISTS (" + subquery + "(rel_transactions.status = ?))) AND (accounts.id > ?)",
+			"[[3] [failed] [10]]"},
+		{"none with filters of the model", QuantifierNone, []FilterListParameter{
+			{FieldOperatorPair{"transactions.amount", OperatorLt}, []string{"0"}},
+			{FieldOperatorPair{"user_id", OperatorEq}, []string{"3"}},
+		}, "(NOT EXISTS (" + subquery + "(rel_transactions.amount < ?))) AND (accounts.user_id = ?)",
+			"[[0] [3]]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			params := NewListParamsFromQuery("", account{})
+			for _, filter := range test.filters {
+				params.AddFilter(filter.Field, filter.Values, filter.Operator)
+			}
+			params.SetQuantifier("transactions", test.quantifier)
+
+			where, arguments := params.GetWhereCondition()
+			if where != test.where {
+				t.Errorf("expected %s, got %s", test.where, where)
+			}
+			if fmt.Sprint(arguments) != test.arguments {
+				t.Errorf("expected arguments %s, got %v", test.arguments, arguments)
+			}
+			if join := params.GetJoinCondition(); join != "" {
+				t.Errorf("expected no joins of has-many relation, got %s", join)
+			}
+		})
+	}
+}
+
+func TestQuantifierParam(t *testing.T) {
+	params := NewListParamsFromQuery("quantifier[transactions]=every&filter[transactions.status]=done", account{})
+	params.AllowFilters([]string{"transactions.status"})
+	if ok, errs := params.Validate(); !ok {
+		t.Fatal(errs)
+	}
+	if quantifier := params.getQuantifier("transactions"); quantifier != QuantifierEvery {
+		t.Errorf("expected %s, got %s", QuantifierEvery, quantifier)
+	}
+
+	params = NewListParamsFromQuery("quantifier[transactions]=all", account{})
+	if ok, errs := params.Validate(); ok || AsParamError(errs[0]).Code != CodeQuantifierInvalid {
+		t.Errorf("expected %s error, got %v", CodeQuantifierInvalid, errs)
+	}
+}
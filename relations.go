@@ -0,0 +1,295 @@
+package list_params
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"github.com/jinzhu/inflection"
+)
+
+type RelationKind string
+
+const (
+	RelationBelongsTo = RelationKind("belongs_to")
+	RelationHasOne    = RelationKind("has_one")
+	RelationHasMany   = RelationKind("has_many")
+)
+
+const relationAliasDelimiter = "__"
+const relationAliasPrefix = "rel_" // Keeps aliases of relations different from reserved words. Example: rel_user
+
+// Relation describes relationship between listed model and related model.
+// Relations are resolved from struct fields and gorm foreignkey and association_foreignkey tags
+// or can be declared by AddRelation
+type Relation struct {
+	Kind       RelationKind
+	Table      string      // Table of related records. Taken from Model if empty
+	ForeignKey string      // Column of foreign key. In parent table for RelationBelongsTo, in related table otherwise
+	References string      // Referenced column. In related table for RelationBelongsTo, in parent table otherwise
+	Model      interface{} // Related model. Needed to resolve nested relations and types of fields
+
+	modelType reflect.Type
+}
+
+// AddRelation declares relation by path. Example: account, account.user
+// Declared relations are preferred over relations resolved from the model
+func (params *ListParams) AddRelation(path string, relation Relation) {
+	if relation.Model != nil {
+		relation.modelType = indirectType(reflect.TypeOf(relation.Model))
+		if relation.Table == "" {
+			relation.Table = getTableName(relation.modelType)
+		}
+	}
+	params.relations[path] = relation
+}
+
+// onStatement returns ON statement joining related table by alias to parent table by parentAlias
+func (r *Relation) onStatement(parentAlias, alias string) string {
+	if r.Kind == RelationBelongsTo {
+		return fmt.Sprintf("%s.%s = %s.%s", alias, r.References, parentAlias, r.ForeignKey)
+	}
+	return fmt.Sprintf("%s.%s = %s.%s", alias, r.ForeignKey, parentAlias, r.References)
+}
+
+// resolveRelationField returns column of related model field with joins needed to select it.
+// Field must be in format [relationship].[field] or [relationship].[relationship].[field]
+func (params *ListParams) resolveRelationField(field string) (column string, joins []join, ok bool) {
+	parts := strings.Split(field, sqlTableFieldDelimiter)
+	if len(parts) < 2 || params.ObjectType == nil {
+		return "", nil, false
+	}
+
+	modelType := indirectType(params.ObjectType)
+	parentAlias := getTableName(modelType)
+	for i := range parts[:len(parts)-1] {
+		path := strings.Join(parts[:i+1], sqlTableFieldDelimiter)
+		relation, ok := params.getRelation(modelType, path)
+		if !ok || relation.Kind == RelationHasMany {
+			return "", nil, false
+		}
+		alias := params.relationAlias(path)
+		joins = append(joins, join{
+			tableName:   relation.Table,
+			alias:       alias,
+			onStatement: relation.onStatement(parentAlias, alias),
+			joinType:    JoinLeft,
+		})
+		modelType = relation.modelType
+		parentAlias = alias
+	}
+
+	column = strings.Join([]string{parentAlias, getColumnName(modelType, parts[len(parts)-1])}, sqlTableFieldDelimiter)
+	return column, joins, true
+}
+
+// getRelation returns declared relation by path or resolves it from the parent model
+func (params *ListParams) getRelation(parentType reflect.Type, path string) (*Relation, bool) {
+	if relation, ok := params.relations[path]; ok {
+		return &relation, true
+	}
+	if parentType == nil {
+		return nil, false
+	}
+	parts := strings.Split(path, sqlTableFieldDelimiter)
+	return resolveRelation(parentType, parts[len(parts)-1])
+}
+
+// relationAlias returns table alias for relationship path. Example: rel_account__user.
+// Alias differs from the table of listed model and from tables and aliases of added joins
+// and joins of custom filters and sortings
+func (params *ListParams) relationAlias(path string) string {
+	alias := relationAliasPrefix + strings.Replace(strcase.ToSnake(path), sqlTableFieldDelimiter, relationAliasDelimiter, -1)
+	candidate := alias
+	for i := 1; params.isTableUsed(candidate); i++ {
+		candidate = fmt.Sprintf("%s_%d", alias, i)
+	}
+	return candidate
+}
+
+func (params *ListParams) isTableUsed(name string) bool {
+	if name == getTableName(indirectType(params.ObjectType)) {
+		return true
+	}
+	for _, v := range params.getAddedJoins() {
+		if v.tableName == name || v.alias == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateIncludeRelations checks relations of includes if VerifyIncludeRelations is enabled
+func (params *ListParams) validateIncludeRelations() {
+	if !params.verifyIncludes {
+		return
+	}
+	includes := params.Includes
+	for _, include := range includes.passedIncludes {
+		if !includes.isAllowedIncludes(include) || includes.isCustomIncludes(include) || includes.isCountIncludes(include) {
+			continue
+		}
+		if _, ok := params.getIncludeRelation(include); !ok {
+			params.addError(newParamError(CodeIncludeRelationNotFound, "include", include, "", "", nil))
+		}
+	}
+}
+
+// getRelationJoins returns joins needed by filters and sortings by relationship fields
+func (params *ListParams) getRelationJoins() []join {
+	fields := make([]string, 0, len(params.Filters)+len(params.Sortings))
+	for _, filter := range params.Filters {
+		if params.getCustomFilter(filter.Field) == nil && !params.isIncludeScopeFilter(&filter) {
+			fields = append(fields, filter.Field)
+		}
+	}
+	for _, enforced := range params.enforcedFilters {
+		if params.getCustomFilter(enforced.Field) == nil {
+			fields = append(fields, enforced.Field)
+		}
+	}
+	for _, sorting := range params.Sortings {
+		if params.getCustomSorting(sorting.Field) == nil {
+			fields = append(fields, sorting.Field)
+		}
+	}
+	for _, group := range params.Groups {
+		field, _, _ := parseGroup(group)
+		fields = append(fields, field)
+	}
+	for _, aggregate := range params.Aggregates {
+		fields = append(fields, aggregate.Field)
+	}
+
+	result := make([]join, 0)
+	for _, field := range fields {
+		_, joins, ok := params.resolveRelationField(field)
+		if !ok {
+			continue
+		}
+		for _, j := range joins {
+			if !isJoinInList(result, j) {
+				result = append(result, j)
+			}
+		}
+	}
+	return result
+}
+
+// resolveRelation resolves relation from struct field of parent model
+// using gorm foreignkey and association_foreignkey tags or gorm naming conventions
+func resolveRelation(parentType reflect.Type, name string) (*Relation, bool) {
+	parentType = indirectType(parentType)
+	if parentType.Kind() != reflect.Struct {
+		return nil, false
+	}
+	field, ok := findStructField(parentType, name)
+	if !ok {
+		return nil, false
+	}
+
+	relatedType := field.Type
+	isSlice := false
+	for relatedType.Kind() == reflect.Ptr || relatedType.Kind() == reflect.Slice {
+		isSlice = isSlice || relatedType.Kind() == reflect.Slice
+		relatedType = relatedType.Elem()
+	}
+	if !isRelationType(relatedType) {
+		return nil, false
+	}
+
+	tags := parseGormTag(field.Tag.Get("gorm"))
+	if _, ok := tags["MANY2MANY"]; ok {
+		return nil, false
+	}
+	relation := &Relation{Table: getTableName(relatedType), modelType: relatedType}
+	foreignKey, hasForeignKey := tags["FOREIGNKEY"]
+	associationKey, hasAssociationKey := tags["ASSOCIATION_FOREIGNKEY"]
+	if !hasAssociationKey {
+		associationKey = "ID"
+	}
+
+	if !isSlice {
+		if !hasForeignKey {
+			foreignKey = field.Name + "ID"
+		}
+		if fkField, ok := parentType.FieldByName(foreignKey); ok {
+			relation.Kind = RelationBelongsTo
+			relation.ForeignKey = getFieldColumn(fkField)
+			relation.References = getColumnName(relatedType, associationKey)
+			return relation, true
+		}
+	}
+
+	if !hasForeignKey {
+		foreignKey = parentType.Name() + "ID"
+	}
+	fkField, ok := relatedType.FieldByName(foreignKey)
+	if !ok {
+		return nil, false
+	}
+	relation.Kind = RelationHasOne
+	if isSlice {
+		relation.Kind = RelationHasMany
+	}
+	relation.ForeignKey = getFieldColumn(fkField)
+	relation.References = getColumnName(parentType, associationKey)
+	return relation, true
+}
+
+// getTableName returns table name of the model from TableName method
+// or plural snake case name of the type
+func getTableName(modelType reflect.Type) string {
+	if method, ok := reflect.PtrTo(modelType).MethodByName(tableNameFuncName); ok {
+		result := method.Func.Call([]reflect.Value{reflect.New(modelType)})
+		return result[0].Interface().(string)
+	}
+	return strcase.ToSnake(inflection.Plural(modelType.Name()))
+}
+
+// getColumnName returns column of model field found by presented or struct field name
+func getColumnName(modelType reflect.Type, name string) string {
+	if modelType != nil && modelType.Kind() == reflect.Struct {
+		if field, ok := findStructField(modelType, name); ok {
+			return getFieldColumn(field)
+		}
+	}
+	return strcase.ToSnake(name)
+}
+
+// getFieldColumn returns column of struct field from db tag, gorm column tag or field name
+func getFieldColumn(field reflect.StructField) string {
+	if dbTag, ok := field.Tag.Lookup("db"); ok {
+		return dbTag
+	}
+	if column, ok := parseGormTag(field.Tag.Get("gorm"))["COLUMN"]; ok {
+		return column
+	}
+	return strcase.ToSnake(field.Name)
+}
+
+// parseGormTag returns options of gorm tag with upper case keys
+func parseGormTag(tag string) map[string]string {
+	options := make(map[string]string)
+	for _, option := range strings.Split(tag, ";") {
+		if option == "" {
+			continue
+		}
+		parts := strings.SplitN(option, ":", 2)
+		key := strings.ToUpper(strings.TrimSpace(parts[0]))
+		if len(parts) == 2 {
+			options[key] = strings.TrimSpace(parts[1])
+		} else {
+			options[key] = ""
+		}
+	}
+	return options
+}
+
+func indirectType(modelType reflect.Type) reflect.Type {
+	for modelType != nil && (modelType.Kind() == reflect.Ptr || modelType.Kind() == reflect.Slice) {
+		modelType = modelType.Elem()
+	}
+	return modelType
+}
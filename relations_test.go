@@ -0,0 +1,47 @@
+package list_params
+
+import "testing"
+
+func TestRelationFieldIsJoinedByPrefixedAlias(t *testing.T) {
+	params := NewListParamsFromQuery("filter[user.email]=a@b.c&sort=user.email", card{})
+	params.AllowFilters([]string{"user.email"})
+	params.AllowSortings([]string{"user.email"})
+	if ok, errs := params.Validate(); !ok {
+		t.Fatal(errs)
+	}
+
+	expectedJoin := "LEFT JOIN users AS rel_user ON rel_user.id = cards.user_id"
+	if join := params.GetJoinCondition(); join != expectedJoin {
+		t.Errorf("expected %s, got %s", expectedJoin, join)
+	}
+	if where, _ := params.GetWhereCondition(); where != "(rel_user.email = ?)" {
+		t.Errorf("expected condition by alias, got %s", where)
+	}
+	if order := params.GetOrderByString(); order != "rel_user.email ASC" {
+		t.Errorf("expected order by alias, got %s", order)
+	}
+}
+
+func TestRelationAliasDiffersFromJoinsOfCustomFilters(t *testing.T) {
+	params := NewListParamsFromQuery("filter[owner]=a@b.c&filter[user.email]=a@b.c", card{})
+	params.AllowFilters([]string{"owner", "user.email"})
+	params.AddCustomFilter("owner", func(values []string, params *ListParams) (string, interface{}) {
+		return "rel_user.email = ?", values[0]
+	}, LeftJoin("users", "rel_user.id = cards.user_id").As("rel_user"))
+
+	expected := "LEFT JOIN users AS rel_user ON rel_user.id = cards.user_id " +
+		"LEFT JOIN users AS rel_user_1 ON rel_user_1.id = cards.user_id"
+	if join := params.GetJoinCondition(); join != expected {
+		t.Errorf("expected %s, got %s", expected, join)
+	}
+}
+
+func TestRelationAliasDiffersFromAddedJoins(t *testing.T) {
+	params := NewListParamsFromQuery("filter[user.email]=a@b.c", card{})
+	params.AllowFilters([]string{"user.email"})
+	params.AddJoinClause(InnerJoin("users", "rel_user.id = cards.user_id").As("rel_user"))
+
+	if where, _ := params.GetWhereCondition(); where != "(rel_user_1.email = ?)" {
+		t.Errorf("expected condition by second alias, got %s", where)
+	}
+}
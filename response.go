@@ -0,0 +1,150 @@
+package list_params
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PaginationMeta describes pagination of the list
+type PaginationMeta struct {
+	Total      int64  `json:"total"`
+	TotalPages uint32 `json:"totalPages"`
+	PageNumber uint32 `json:"pageNumber"`
+	PageSize   uint32 `json:"pageSize"`
+}
+
+// Links contains urls of the current, first, previous, next and last pages
+type Links struct {
+	Self  string `json:"self"`
+	First string `json:"first,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last,omitempty"`
+}
+
+// ListResponse is response body with list of records, pagination meta and links
+type ListResponse struct {
+	Data  interface{}    `json:"data"`
+	Meta  PaginationMeta `json:"meta"`
+	Links Links          `json:"links"`
+}
+
+// NewListResponse creates ListResponse for records loaded by params.
+// total is count of all records matching filters, path is path of the list endpoint
+func NewListResponse(data interface{}, params *ListParams, total int64, path string) *ListResponse {
+	return &ListResponse{
+		Data:  data,
+		Meta:  params.GetPaginationMeta(total),
+		Links: params.GetLinks(path, total),
+	}
+}
+
+// GetPaginationMeta returns pagination meta for total count of records
+func (params *ListParams) GetPaginationMeta(total int64) PaginationMeta {
+	meta := PaginationMeta{
+		Total:      total,
+		TotalPages: 1,
+		PageNumber: params.Pagination.PageNumber,
+		PageSize:   params.Pagination.PageSize,
+	}
+	if size := int64(params.Pagination.PageSize); size != 0 {
+		meta.TotalPages = uint32((total + size - 1) / size)
+	}
+	return meta
+}
+
+// GetLinks returns links to pages of the list. Links keep all passed list params
+func (params *ListParams) GetLinks(path string, total int64) Links {
+	meta := params.GetPaginationMeta(total)
+	links := Links{Self: params.pageLink(path, meta.PageNumber)}
+	if params.Pagination.PageSize == 0 {
+		return links
+	}
+
+	lastPage := meta.TotalPages
+	if lastPage == 0 {
+		lastPage = DefaultPageNumber
+	}
+	links.First = params.pageLink(path, DefaultPageNumber)
+	links.Last = params.pageLink(path, lastPage)
+	if meta.PageNumber > DefaultPageNumber {
+		links.Prev = params.pageLink(path, meta.PageNumber-1)
+	}
+	if meta.PageNumber < lastPage {
+		links.Next = params.pageLink(path, meta.PageNumber+1)
+	}
+	return links
+}
+
+// Encode returns url query with passed list params
+func (params *ListParams) Encode() string {
+	return params.queryValues().Encode()
+}
+
+func (params *ListParams) pageLink(path string, pageNumber uint32) string {
+	values := params.queryValues()
+	values.Set("page[number]", strconv.FormatUint(uint64(pageNumber), 10))
+	return path + "?" + values.Encode()
+}
+
+// queryValues returns list params in the format of url query
+func (params *ListParams) queryValues() url.Values {
+	values := make(url.Values)
+	if len(params.Sortings) != 0 {
+		values.Set("sort", params.encodeSortings())
+	}
+	for _, filter := range params.Filters {
+		values.Set(filterParameter(filter.Field, filter.Operator), strings.Join(filter.Values, queryParamDelimiter))
+	}
+	if len(params.Groups) != 0 {
+		values.Set(groupParameter, strings.Join(params.Groups, queryParamDelimiter))
+	}
+	if len(params.Aggregates) != 0 {
+		values.Set(aggregateParameter, params.encodeAggregates())
+	}
+	for _, having := range params.Havings {
+		values.Set(havingParameter(having.Field, having.Operator), strings.Join(having.Values, queryParamDelimiter))
+	}
+	if params.timezone != nil {
+		values.Set(timezoneParameter, params.timezone.String())
+	}
+	for relation, quantifier := range params.quantifiers {
+		values.Set(fmt.Sprintf("quantifier[%s]", relation), string(quantifier))
+	}
+	if params.Includes != nil && len(params.Includes.passedIncludes) != 0 {
+		values.Set("include", strings.Join(params.Includes.passedIncludes, queryParamDelimiter))
+	}
+	for key, fields := range params.sparseFields {
+		parameter := fieldsParameter
+		if key != "" {
+			parameter = fmt.Sprintf("%s[%s]", fieldsParameter, key)
+		}
+		values.Set(parameter, strings.Join(fields, queryParamDelimiter))
+	}
+	for include, scope := range params.includeScopes {
+		if len(scope.Sortings) != 0 {
+			values.Set(fmt.Sprintf("sort[%s]", include), scope.encodeSortings())
+		}
+		if scope.Pagination.PageSize != 0 {
+			values.Set(fmt.Sprintf("page[%s][number]", include), strconv.FormatUint(uint64(scope.Pagination.PageNumber), 10))
+			values.Set(fmt.Sprintf("page[%s][size]", include), strconv.FormatUint(uint64(scope.Pagination.PageSize), 10))
+		}
+	}
+	values.Set("page[number]", strconv.FormatUint(uint64(params.Pagination.PageNumber), 10))
+	values.Set("page[size]", strconv.FormatUint(uint64(params.Pagination.PageSize), 10))
+	return values
+}
+
+// encodeSortings returns sortings in the format of sort param
+func (params *ListParams) encodeSortings() string {
+	sortings := make([]string, len(params.Sortings))
+	for i, sorting := range params.Sortings {
+		sortings[i] = sorting.Field
+		if sorting.isDescDirection() {
+			sortings[i] = "-" + sorting.Field
+		}
+	}
+	return strings.Join(sortings, queryParamDelimiter)
+}
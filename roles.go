@@ -0,0 +1,143 @@
+package list_params
+
+import (
+	"context"
+)
+
+type rolesContextKey struct{}
+
+// RoleProfile describes list params allowed for a role
+type RoleProfile struct {
+	Filters      []string // See AllowFilters
+	Sortings     []string // See AllowSortings
+	Includes     []string // See AllowIncludes
+	SelectFields []interface{}
+	Pagination   bool
+}
+
+// NewRolesContext returns context with roles of the caller
+func NewRolesContext(ctx context.Context, roles ...string) context.Context {
+	return context.WithValue(ctx, rolesContextKey{}, roles)
+}
+
+// RolesFromContext returns roles of the caller stored by NewRolesContext
+func RolesFromContext(ctx context.Context) []string {
+	roles, _ := ctx.Value(rolesContextKey{}).([]string)
+	return roles
+}
+
+// AddRoleProfile adds list params allowed for the role.
+// Profiles of roles taken from context of params are applied by Validate, see ApplyRoles
+func (params *ListParams) AddRoleProfile(role string, profile RoleProfile) {
+	params.roleProfiles[role] = profile
+}
+
+// ApplyRoles allows union of list params allowed by profiles of the roles
+// in addition to list params allowed for all roles. Profiles are applied only once.
+// Output policy of a field is dropped if any role allows the field without policy
+func (params *ListParams) ApplyRoles(roles ...string) {
+	if params.rolesApplied {
+		return
+	}
+	params.rolesApplied = true
+	for _, role := range roles {
+		profile, ok := params.roleProfiles[role]
+		if !ok {
+			continue
+		}
+		for _, field := range profile.Filters {
+			name, operator := params.parseField(field)
+			if !params.isAllowedFilter(name, operator) {
+				params.allowedListParams.Filters = append(params.allowedListParams.Filters,
+					FieldOperatorPair{Field: name, Operator: operator})
+			}
+		}
+		for _, field := range profile.Sortings {
+			if !params.isAllowedSorting(field) {
+				params.allowedListParams.Sortings = append(params.allowedListParams.Sortings, field)
+			}
+		}
+		params.Includes.allowedIncludes = appendMissing(params.Includes.allowedIncludes, profile.Includes)
+		if profile.SelectFields != nil {
+			profileIncludes := &Includes{policies: make(map[string]OutputPolicy), modelType: params.ObjectType}
+			profileFields := profileIncludes.resolveAllowedFields(
+				profileIncludes.setOutputPolicies(profile.SelectFields, ""))
+			params.Includes.policies = mergeOutputPolicies(params.Includes.policies, params.Includes.fieldsSet,
+				profileIncludes.policies, profileFields)
+			fields := InterfaceArrayToFields(params.Includes.fieldsSet)
+			merged := fields.Merge(InterfaceArrayToFields(profileFields))
+			params.Includes.fieldsSet = merged.ToInterfaceArray()
+		}
+		params.allowedListParams.Pagination = params.allowedListParams.Pagination || profile.Pagination
+	}
+}
+
+// applyContextRoles applies profiles of roles taken from context of params
+func (params *ListParams) applyContextRoles() {
+	if len(params.roleProfiles) != 0 {
+		params.ApplyRoles(RolesFromContext(params.Context())...)
+	}
+}
+
+// isFilterOfAnyRole returns true if the filter is allowed by profile of any role
+func (params *ListParams) isFilterOfAnyRole(field string, operator Operator) bool {
+	for _, profile := range params.roleProfiles {
+		for _, v := range profile.Filters {
+			if name, op := params.parseField(v); name == field && op == operator {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isSortingOfAnyRole returns true if the sorting is allowed by profile of any role
+func (params *ListParams) isSortingOfAnyRole(field string) bool {
+	for _, profile := range params.roleProfiles {
+		for _, v := range profile.Sortings {
+			if v == field {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isIncludeOfAnyRole returns true if the include is allowed by profile of any role
+func (params *ListParams) isIncludeOfAnyRole(field string) bool {
+	for _, profile := range params.roleProfiles {
+		for _, v := range profile.Includes {
+			if matchIncludePattern(v, field) || isImpliedParent(v, field) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// forbidIncludesErrors replaces errors of includes allowed for other roles by forbidden errors
+func (params *ListParams) forbidIncludesErrors(errors []error) []error {
+	for i, err := range errors {
+		paramErr := AsParamError(err)
+		if paramErr.Code == CodeIncludeNotAllowed && params.isIncludeOfAnyRole(paramErr.Field) {
+			errors[i] = newParamError(CodeIncludeForbidden, paramErr.Parameter, paramErr.Field, "", "", nil)
+		}
+	}
+	return errors
+}
+
+func appendMissing(list []string, values []string) []string {
+	for _, value := range values {
+		found := false
+		for _, v := range list {
+			if v == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	return list
+}
@@ -0,0 +1,145 @@
+package list_params
+
+import (
+	"reflect"
+	"strings"
+)
+
+const fieldsExclusionPrefix = "-"
+
+// levelFieldsFunc returns all fields of nested level by path of relation names
+type levelFieldsFunc func(path []string) Fields
+
+// resolveAllowedFields expands wildcards and exclusions of allowed fields.
+// Wildcard allows all columns of the model on its level, relations have to be listed explicitly
+func (self *Includes) resolveAllowedFields(fields []interface{}) []interface{} {
+	resolved := resolveFieldsWildcards(NewFields(), fields, nil, func(path []string) Fields {
+		return getModelLevelFields(self.modelType, path)
+	})
+	return resolved.ToInterfaceArray()
+}
+
+// resolveSelectedFields expands wildcards and exclusions of selected fields against allowed fields.
+// Wildcard selects all allowed fields on its level including nested relations
+func resolveSelectedFields(fields []interface{}, allowed Fields) Fields {
+	return resolveFieldsWildcards(NewFields(), fields, nil, func(path []string) Fields {
+		level := &allowed
+		for _, name := range path {
+			if level = level.getNestedFields(name); level == nil {
+				return NewFields()
+			}
+		}
+		return level.copy()
+	})
+}
+
+// resolveFieldsWildcards adds to the level fields in format of AllowSelectFields with wildcards,
+// exclusions and dotted paths. Example: *, -password, author.*, {"Account": ["*", "-secret"]}
+func resolveFieldsWildcards(level Fields, fields []interface{}, path []string, levelFields levelFieldsFunc) Fields {
+	result := level.copy()
+	excluded := make([]string, 0)
+	nested := make(map[string][]interface{})
+	nestedOrder := make([]string, 0)
+	addNested := func(name string, values ...interface{}) {
+		if _, ok := nested[name]; !ok {
+			nestedOrder = append(nestedOrder, name)
+		}
+		nested[name] = append(nested[name], values...)
+	}
+
+	for _, field := range fields {
+		name, ok := field.(string)
+		if !ok {
+			for key, values := range field.(map[string][]interface{}) {
+				addNested(key, values...)
+			}
+			continue
+		}
+		exclusion := strings.HasPrefix(name, fieldsExclusionPrefix)
+		name = strings.TrimPrefix(name, fieldsExclusionPrefix)
+		if splitField := strings.SplitN(name, defaultFieldsDelimiter, 2); len(splitField) == 2 {
+			if exclusion {
+				splitField[1] = fieldsExclusionPrefix + splitField[1]
+			}
+			addNested(splitField[0], splitField[1])
+			continue
+		}
+		switch {
+		case exclusion:
+			excluded = append(excluded, name)
+		case name == includesWildcard:
+			all := levelFields(path)
+			result = result.Merge(all)
+		default:
+			result.Add(name)
+		}
+	}
+
+	for _, name := range nestedOrder {
+		nestedPath := append(append(make([]string, 0, len(path)+1), path...), name)
+		if existing := result.getNestedFields(name); existing != nil {
+			*existing = resolveFieldsWildcards(*existing, nested[name], nestedPath, levelFields)
+		} else {
+			result.NestedFields = append(result.NestedFields,
+				resolveFieldsWildcards(newFields(name), nested[name], nestedPath, levelFields))
+		}
+	}
+
+	for _, name := range excluded {
+		result.remove(name)
+	}
+	return result
+}
+
+// remove removes field or nested model of the level
+func (f *Fields) remove(name string) {
+	list := make([]string, 0, len(f.List))
+	for _, v := range f.List {
+		if v != name {
+			list = append(list, v)
+		}
+	}
+	nestedFields := make([]Fields, 0, len(f.NestedFields))
+	for _, v := range f.NestedFields {
+		if v.PropName != name {
+			nestedFields = append(nestedFields, v)
+		}
+	}
+	f.List, f.NestedFields = list, nestedFields
+}
+
+// getModelLevelFields returns names of columns of the model or its relation by path of relation names
+func getModelLevelFields(modelType reflect.Type, path []string) Fields {
+	result := NewFields()
+	modelType = indirectType(modelType)
+	for _, name := range path {
+		if modelType == nil || modelType.Kind() != reflect.Struct {
+			return result
+		}
+		field, ok := getProjectedField(modelType, name)
+		if !ok {
+			return result
+		}
+		modelType = indirectType(field.Type)
+	}
+	if modelType == nil || modelType.Kind() != reflect.Struct {
+		return result
+	}
+	addModelColumns(&result, modelType)
+	return result
+}
+
+// addModelColumns adds exported fields of the model except relations. Fields of embedded structs are added too
+func addModelColumns(fields *Fields, modelType reflect.Type) {
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		if field.Anonymous && indirectType(field.Type).Kind() == reflect.Struct {
+			addModelColumns(fields, indirectType(field.Type))
+			continue
+		}
+		if field.PkgPath != "" || jsonName(field) == "-" || isRelationType(field.Type) {
+			continue
+		}
+		fields.Add(field.Name)
+	}
+}
@@ -0,0 +1,133 @@
+package list_params
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Project returns record with output fields only, see GetOutputFields.
+// Record can be struct or pointer to struct, nested relations can be structs, pointers or slices.
+// Keys are names from json tags, output policies are applied.
+// All fields are returned if select fields are not allowed
+func (params *ListParams) Project(record interface{}) (map[string]interface{}, error) {
+	if len(params.Includes.fieldsSet) == 0 {
+		return marshalToMap(record)
+	}
+	value := reflect.ValueOf(record)
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil, nil
+		}
+		value = value.Elem()
+	}
+	return params.Includes.projectStruct(value, params.GetOutputFields(), ""), nil
+}
+
+// ProjectList returns records with output fields only, see Project. Pass slice or pointer to slice
+func (params *ListParams) ProjectList(records interface{}) ([]map[string]interface{}, error) {
+	slice := reflect.Indirect(reflect.ValueOf(records))
+	result := make([]map[string]interface{}, slice.Len())
+	for i := 0; i < slice.Len(); i++ {
+		record, err := params.Project(slice.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		result[i] = record
+	}
+	return result, nil
+}
+
+// MarshalList returns JSON array of records with output fields only, see Project
+func (params *ListParams) MarshalList(records interface{}) ([]byte, error) {
+	result, err := params.ProjectList(records)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
+
+// projectStruct returns fields of struct value by output fields with keys from json tags
+func (self *Includes) projectStruct(value reflect.Value, fields []interface{}, path string) map[string]interface{} {
+	result := make(map[string]interface{})
+	for _, field := range fields {
+		if name, ok := field.(string); ok {
+			structField, ok := getProjectedField(value.Type(), name)
+			if !ok {
+				continue
+			}
+			policy, hasPolicy := self.policies[normalizeFieldPath(joinFieldPath(path, name))]
+			fieldValue := value.FieldByIndex(structField.Index).Interface()
+			if !hasPolicy {
+				result[projectedKey(structField)] = fieldValue
+			} else if policy != PolicyHide {
+				result[projectedKey(structField)] = applyOutputPolicy(policy, fieldValue)
+			}
+			continue
+		}
+		for name, nestedFields := range field.(map[string][]interface{}) {
+			structField, ok := getProjectedField(value.Type(), name)
+			if !ok {
+				continue
+			}
+			result[projectedKey(structField)] = self.projectValue(value.FieldByIndex(structField.Index),
+				nestedFields, joinFieldPath(path, name))
+		}
+	}
+	return result
+}
+
+// projectValue returns nested relation projected by output fields
+func (self *Includes) projectValue(value reflect.Value, fields []interface{}, path string) interface{} {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		return self.projectStruct(value, fields, path)
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return []interface{}{}
+		}
+		result := make([]interface{}, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			result[i] = self.projectValue(value.Index(i), fields, path)
+		}
+		return result
+	}
+	return value.Interface()
+}
+
+// getProjectedField returns struct field by name of output field. Fields skipped by json are not returned
+func getProjectedField(modelType reflect.Type, name string) (reflect.StructField, bool) {
+	field, ok := modelType.FieldByName(name)
+	if !ok {
+		field, ok = findStructField(modelType, name)
+	}
+	if !ok || jsonName(field) == "-" || field.PkgPath != "" {
+		return reflect.StructField{}, false
+	}
+	return field, true
+}
+
+// projectedKey returns key of struct field from json tag or name of the field
+func projectedKey(field reflect.StructField) string {
+	if name := jsonName(field); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+func marshalToMap(record interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
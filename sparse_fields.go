@@ -0,0 +1,182 @@
+package list_params
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const fieldsParameter = "fields"
+
+// setSparseFields takes fields requested by fields and fields[type] params.
+// Example: fields=id,amount,account.currency or fields[accounts]=id,currency
+func (params *ListParams) setSparseFields(values url.Values) {
+	pattern := regexp.MustCompile(`^fields(\[(.*)\])?$`)
+	for k, v := range values {
+		submatches := pattern.FindStringSubmatch(k)
+		if len(submatches) < 3 || len(v) == 0 {
+			continue
+		}
+		fields := make([]string, 0)
+		for _, value := range v {
+			for _, field := range strings.Split(value, queryParamDelimiter) {
+				if field != "" {
+					fields = append(fields, field)
+				}
+			}
+		}
+		params.sparseFields[submatches[2]] = fields
+	}
+}
+
+// applySparseFields validates requested fields against allowed select fields and selects them
+// replacing fields set by SelectFields before. Primary keys and keys needed to preload requested includes
+// are selected automatically even if they are not allowed, but they are not output
+func (params *ListParams) applySparseFields() {
+	if len(params.sparseFields) == 0 || len(params.Includes.fieldsSet) == 0 {
+		return
+	}
+	allowedPaths := selectFieldPaths(params.Includes.fieldsSet, "")
+	explicit := make(map[string]map[string]bool)
+	valid := true
+	for key, fields := range params.sparseFields {
+		parameter := fieldsParameter
+		if key != "" {
+			parameter = fmt.Sprintf("%s[%s]", fieldsParameter, key)
+		}
+		level, ok := params.getSparseFieldsLevel(key, allowedPaths)
+		if !ok {
+			params.addError(newParamError(CodeFieldNotAllowed, parameter, key, "", "", nil))
+			valid = false
+			continue
+		}
+		for _, field := range fields {
+			path := normalizeFieldPath(joinFieldPath(level, field))
+			if !allowedPaths[path] {
+				params.addError(newParamError(CodeFieldNotAllowed, parameter, field, "", "", nil))
+				valid = false
+				continue
+			}
+			fieldLevel, name := "", path
+			if i := strings.LastIndex(path, includesSeparator); i != -1 {
+				fieldLevel, name = path[:i], path[i+1:]
+			}
+			if explicit[fieldLevel] == nil {
+				explicit[fieldLevel] = make(map[string]bool)
+			}
+			explicit[fieldLevel][name] = true
+		}
+	}
+	if !valid {
+		return
+	}
+
+	selected, keys := selectSparseFields(params.Includes.fieldsSet, "", explicit, params.getRequiredKeys())
+	params.SelectFields(selected)
+	params.Includes.requiredFields = keys
+}
+
+// getSparseFieldsLevel returns normalized path of nested fields by key of fields param.
+// Key is empty for the listed model, relation path or table of the listed or included model
+func (params *ListParams) getSparseFieldsLevel(key string, allowedPaths map[string]bool) (string, bool) {
+	if key == "" || (params.ObjectType != nil && key == getTableName(indirectType(params.ObjectType))) {
+		return "", true
+	}
+	level := normalizeFieldPath(key)
+	for path := range allowedPaths {
+		if strings.HasPrefix(path, level+includesSeparator) {
+			return level, true
+		}
+	}
+	for _, include := range params.Includes.passedIncludes {
+		if relation, ok := params.getIncludeRelation(include); ok && relation.Table == key {
+			return normalizeFieldPath(include), true
+		}
+	}
+	return "", false
+}
+
+// getRequiredKeys returns names of fields of primary keys and keys of requested includes by normalized nested paths
+func (params *ListParams) getRequiredKeys() map[string][]string {
+	required := make(map[string][]string)
+	if params.ObjectType == nil {
+		return required
+	}
+	rootType := indirectType(params.ObjectType)
+	required[""] = []string{getColumnFieldName(rootType, getPrimaryKeyColumn(rootType))}
+	for _, include := range params.Includes.getNotCustomIncludes() {
+		parts := strings.Split(include, includesSeparator)
+		parentType := rootType
+		for i := range parts {
+			relation, ok := params.getIncludeRelation(strings.Join(parts[:i+1], includesSeparator))
+			if !ok || relation.modelType == nil {
+				break
+			}
+			parentLevel := normalizeFieldPath(strings.Join(parts[:i], includesSeparator))
+			level := normalizeFieldPath(strings.Join(parts[:i+1], includesSeparator))
+			if relation.Kind == RelationBelongsTo {
+				required[parentLevel] = append(required[parentLevel], getColumnFieldName(parentType, relation.ForeignKey))
+				required[level] = append(required[level], getColumnFieldName(relation.modelType, relation.References))
+			} else {
+				required[parentLevel] = append(required[parentLevel], getColumnFieldName(parentType, relation.References))
+				required[level] = append(required[level], getColumnFieldName(relation.modelType, relation.ForeignKey))
+			}
+			required[level] = append(required[level],
+				getColumnFieldName(relation.modelType, getPrimaryKeyColumn(relation.modelType)))
+			parentType = relation.modelType
+		}
+	}
+	return required
+}
+
+// getColumnFieldName returns name of the model field stored in the column. Returns the column if field is not found
+func getColumnFieldName(modelType reflect.Type, column string) string {
+	for i := 0; i < modelType.NumField(); i++ {
+		if field := modelType.Field(i); field.PkgPath == "" && getFieldColumn(field) == column {
+			return field.Name
+		}
+	}
+	return column
+}
+
+// selectSparseFields returns allowed fields in format of AllowSelectFields narrowed by explicitly requested fields
+// and required keys of levels with requested fields in the same format.
+// All allowed fields are selected on levels without requested fields
+func selectSparseFields(allowed []interface{}, level string, explicit map[string]map[string]bool,
+	required map[string][]string) ([]interface{}, []interface{}) {
+	result := make([]interface{}, 0, len(allowed))
+	keys := make([]interface{}, 0)
+	selected := make(map[string]bool)
+	requested, narrowed := explicit[level]
+	for _, field := range allowed {
+		if reflect.ValueOf(field).Kind() == reflect.String {
+			if name := normalizeFieldPath(field.(string)); !narrowed || requested[name] {
+				result = append(result, field)
+				selected[name] = true
+			}
+			continue
+		}
+		nested := make(map[string][]interface{})
+		for key, values := range field.(map[string][]interface{}) {
+			var nestedKeys []interface{}
+			nested[key], nestedKeys = selectSparseFields(values, normalizeFieldPath(joinFieldPath(level, key)),
+				explicit, required)
+			if len(nestedKeys) != 0 {
+				keys = append(keys, map[string][]interface{}{key: nestedKeys})
+			}
+		}
+		result = append(result, nested)
+	}
+	if !narrowed {
+		return result, keys
+	}
+	for _, key := range required[level] {
+		if name := normalizeFieldPath(key); !selected[name] {
+			keys = append(keys, key)
+			selected[name] = true
+		}
+	}
+	return result, keys
+}
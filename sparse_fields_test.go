@@ -0,0 +1,62 @@
+package list_params
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func newSparseFieldsParams(query string) *ListParams {
+	params := NewListParamsFromQuery(query, card{})
+	params.AllowSelectFields([]interface{}{"Currency", "Secret", map[string][]interface{}{"User": {"Email"}}})
+	params.AllowIncludes([]string{"user"})
+	return params
+}
+
+func TestSparseFieldsSelectKeysWithoutOutput(t *testing.T) {
+	params := newSparseFieldsParams("fields=currency&include=user")
+	if ok, errs := params.Validate(); !ok {
+		t.Fatal(errs)
+	}
+
+	if output := fmt.Sprint(params.GetOutputFields()); output != "[Currency map[User:[Email]]]" {
+		t.Errorf("expected requested fields only, got %s", output)
+	}
+	selects := params.GetSelectQuery()
+	sort.Strings(selects)
+	if fmt.Sprint(selects) != "[Currency ID UserID user.Email]" {
+		t.Errorf("expected requested fields and keys, got %v", selects)
+	}
+}
+
+func TestSparseFieldsOfIncludedType(t *testing.T) {
+	params := newSparseFieldsParams("fields[cards]=secret&fields[user]=email&include=user")
+	if ok, errs := params.Validate(); !ok {
+		t.Fatal(errs)
+	}
+
+	if output := fmt.Sprint(params.GetOutputFields()); output != "[Secret map[User:[Email]]]" {
+		t.Errorf("expected requested fields only, got %s", output)
+	}
+}
+
+func TestSparseFieldsNotAllowed(t *testing.T) {
+	tests := []string{"fields=amount", "fields=user.id&include=user", "fields[accounts]=id"}
+	for _, query := range tests {
+		params := newSparseFieldsParams(query)
+		if ok, errs := params.Validate(); ok || AsParamError(errs[0]).Code != CodeFieldNotAllowed {
+			t.Errorf("%s: expected %s error, got %v", query, CodeFieldNotAllowed, errs)
+		}
+	}
+}
+
+func TestWithoutSparseFieldsAllowedFieldsAreOutput(t *testing.T) {
+	params := newSparseFieldsParams("include=user")
+	if ok, errs := params.Validate(); !ok {
+		t.Fatal(errs)
+	}
+
+	if output := fmt.Sprint(params.GetOutputFields()); output != "[Currency Secret map[User:[Email]]]" {
+		t.Errorf("expected allowed fields, got %s", output)
+	}
+}
@@ -0,0 +1,101 @@
+package list_params
+
+// Spec describes list params allowed for an endpoint.
+// Can be shared between requests, ListParams are created per request by NewListParams
+type Spec struct {
+	Object       interface{} // Model of listed records. Example: Transaction{}
+	Filters      []string    // See AllowFilters
+	Sortings     []string    // See AllowSortings
+	Includes     []string    // See AllowIncludes
+	SelectFields []interface{}
+	Pagination   bool
+	Relations    map[string]Relation // Declared relations by paths, see AddRelation
+	Counts       []string            // Countable has-many relations, see AddCountableRelation
+	Groups       []string            // See AllowGroups
+	Aggregates   []string            // See AllowAggregates
+
+	MaxIncludeDepth int  // See SetMaxIncludeDepth
+	VerifyIncludes  bool // See VerifyIncludeRelations
+
+	// Enforced are mandatory conditions by fields in format of Filters, see EnforceFromContext
+	Enforced map[string]EnforcedValues
+	// ConflictPolicy handles client filters by enforced fields, see SetConflictPolicy
+	ConflictPolicy ConflictPolicy
+
+	// Roles are list params allowed for roles in addition to list params allowed for all roles.
+	// Roles of the caller are taken from context, see NewRolesContext and AddRoleProfile
+	Roles map[string]RoleProfile
+
+	// IncludeScopes allows filters, sortings and pagination of included relations by include paths, see IncludeParams
+	IncludeScopes map[string]IncludeScope
+
+	// Configure is called for every created ListParams.
+	// Can be used to add custom filters, sortings, includes, joins and validators
+	Configure func(params *ListParams)
+}
+
+// IncludeScope describes list params allowed for included relation
+type IncludeScope struct {
+	Filters    []string // See AllowFilters
+	Sortings   []string // See AllowSortings
+	Pagination bool
+}
+
+// NewListParams creates ListParams from passed url query and applies the spec
+func (spec *Spec) NewListParams(query string) *ListParams {
+	params := NewListParamsFromQuery(query, spec.Object)
+	spec.Apply(params)
+	return params
+}
+
+// Apply sets allowed options of the spec to params
+func (spec *Spec) Apply(params *ListParams) {
+	params.AllowFilters(spec.Filters)
+	params.AllowSortings(spec.Sortings)
+	params.AllowIncludes(spec.Includes)
+	if spec.SelectFields != nil {
+		params.AllowSelectFields(spec.SelectFields)
+	}
+	if spec.Pagination {
+		params.AllowPagination()
+	}
+	for path, relation := range spec.Relations {
+		params.AddRelation(path, relation)
+	}
+	for _, relation := range spec.Counts {
+		params.AddCountableRelation(relation)
+	}
+	if spec.Groups != nil {
+		params.AllowGroups(spec.Groups)
+	}
+	if spec.Aggregates != nil {
+		params.AllowAggregates(spec.Aggregates)
+	}
+	if spec.MaxIncludeDepth != 0 {
+		params.SetMaxIncludeDepth(spec.MaxIncludeDepth)
+	}
+	if spec.VerifyIncludes {
+		params.VerifyIncludeRelations()
+	}
+	for role, profile := range spec.Roles {
+		params.AddRoleProfile(role, profile)
+	}
+	for fieldWithOperator, values := range spec.Enforced {
+		field, operator := params.parseField(fieldWithOperator)
+		params.EnforceFromContext(field, values, operator)
+	}
+	if spec.ConflictPolicy != "" {
+		params.SetConflictPolicy(spec.ConflictPolicy)
+	}
+	for include, scope := range spec.IncludeScopes {
+		includeParams := params.IncludeParams(include)
+		includeParams.AllowFilters(scope.Filters)
+		includeParams.AllowSortings(scope.Sortings)
+		if scope.Pagination {
+			includeParams.AllowPagination()
+		}
+	}
+	if spec.Configure != nil {
+		spec.Configure(params)
+	}
+}
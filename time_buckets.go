@@ -0,0 +1,304 @@
+package list_params
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const timezoneParameter = "tz"
+const maxTimeBuckets = 10000
+const bucketTimeLayout = "2006-01-02 15:04:05"
+
+// TimeBucket is unit of time used to group records by time field
+type TimeBucket string
+
+const (
+	BucketHour  = TimeBucket("hour")
+	BucketDay   = TimeBucket("day")
+	BucketWeek  = TimeBucket("week")
+	BucketMonth = TimeBucket("month")
+	BucketYear  = TimeBucket("year")
+)
+
+var knownTimeBuckets = map[string]TimeBucket{
+	"hour":  BucketHour,
+	"day":   BucketDay,
+	"week":  BucketWeek,
+	"month": BucketMonth,
+	"year":  BucketYear,
+}
+
+var bucketTimeLayouts = []string{bucketTimeLayout, dateLayout, time.RFC3339Nano}
+
+// SetTimezone sets timezone of time buckets. Passed by tz param as IANA name. Example: tz=Europe/Madrid.
+// Local timezone of the server can not be passed
+func (params *ListParams) SetTimezone(location *time.Location) {
+	params.timezone = location
+}
+
+// GetTimezone returns timezone of time buckets. UTC by default
+func (params *ListParams) GetTimezone() *time.Location {
+	if params.timezone == nil {
+		return time.UTC
+	}
+	return params.timezone
+}
+
+// FillTimeBuckets adds rows with empty aggregates for buckets of time group without records.
+// Buckets are filled between bounds of filters by the time field or between first and last loaded buckets
+// for every combination of values of other groups. Counts and sums of empty buckets are zero,
+// other aggregates are nil. Rows are ordered by buckets, descending if the time field is sorted descending.
+// Rows without bucket are added to the end. Rows are returned as is if page size is passed
+// or the list is filtered by having filters, because filled buckets would not match the page or the filters
+func (params *ListParams) FillTimeBuckets(rows []AggregateRow) ([]AggregateRow, error) {
+	field, bucket, ok := params.getTimeBucketGroup()
+	if !ok || params.HasPageSize() || len(params.Havings) != 0 {
+		return rows, nil
+	}
+	location := params.GetTimezone()
+	series := make(map[string]map[int64]AggregateRow)
+	seriesGroups := make(map[string]map[string]interface{})
+	order := make([]string, 0)
+	noBucket := make([]AggregateRow, 0)
+	var from, to time.Time
+	for _, row := range rows {
+		value, ok := row.Groups[field].(time.Time)
+		if !ok {
+			noBucket = append(noBucket, row)
+			continue
+		}
+		value = truncateTime(value.In(location), bucket)
+		key := params.getSeriesKey(row.Groups, field)
+		if _, ok := series[key]; !ok {
+			series[key] = make(map[int64]AggregateRow)
+			seriesGroups[key] = row.Groups
+			order = append(order, key)
+		}
+		series[key][value.Unix()] = row
+		if from.IsZero() || value.Before(from) {
+			from = value
+		}
+		if to.IsZero() || value.After(to) {
+			to = value
+		}
+	}
+	if filterFrom, filterTo, hasFrom, hasTo := params.getTimeBucketRange(field, bucket); hasFrom || hasTo {
+		if hasFrom {
+			from = filterFrom
+		}
+		if hasTo {
+			to = filterTo
+		}
+		if len(order) == 0 && len(params.Groups) == 1 {
+			series[""] = make(map[int64]AggregateRow)
+			seriesGroups[""] = map[string]interface{}{}
+			order = append(order, "")
+		}
+	}
+	if len(order) == 0 || from.IsZero() || to.IsZero() || to.Before(from) {
+		return rows, nil
+	}
+
+	buckets := make([]time.Time, 0)
+	for value := from; !value.After(to); value = nextBucket(value, bucket) {
+		if len(buckets) == maxTimeBuckets {
+			return nil, fmt.Errorf("Too many time buckets: must be at most %d", maxTimeBuckets)
+		}
+		buckets = append(buckets, value)
+	}
+	if params.isSortedDesc(field) {
+		sort.Slice(buckets, func(i, j int) bool { return buckets[i].After(buckets[j]) })
+	}
+
+	result := make([]AggregateRow, 0, len(buckets)*len(order)+len(noBucket))
+	for _, value := range buckets {
+		for _, key := range order {
+			row, ok := series[key][value.Unix()]
+			if !ok {
+				row = params.newEmptyAggregateRow(seriesGroups[key], field, value)
+			}
+			result = append(result, row)
+		}
+	}
+	return append(result, noBucket...), nil
+}
+
+// setTimezone takes timezone passed by tz param. Invalid timezone is added to errors
+func (params *ListParams) setTimezone(values url.Values) {
+	name := values.Get(timezoneParameter)
+	if name == "" {
+		return
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil || name == time.Local.String() || strings.Contains(name, "'") {
+		params.addError(newParamError(CodeTimezoneInvalid, timezoneParameter, "", "", name, nil))
+		return
+	}
+	params.timezone = location
+}
+
+// getGroupColumn returns column of group field or time bucket expression of the column
+func (params *ListParams) getGroupColumn(group string) string {
+	field, bucket, _ := parseGroup(group)
+	column := params.getFieldColumn(field)
+	if bucket == "" {
+		return column
+	}
+	return params.dialect.TimeBucket(column, bucket, params.GetTimezone())
+}
+
+// getTimeBucketGroup returns field and bucket of the first requested time group
+func (params *ListParams) getTimeBucketGroup() (string, TimeBucket, bool) {
+	for _, group := range params.Groups {
+		if field, bucket, ok := parseGroup(group); ok && bucket != "" {
+			return field, bucket, true
+		}
+	}
+	return "", "", false
+}
+
+// getBucketGroup returns group with time bucket by name of its field
+func (params *ListParams) getBucketGroup(field string) (string, bool) {
+	for _, group := range params.Groups {
+		if name, bucket, ok := parseGroup(group); ok && bucket != "" && name == field {
+			return group, true
+		}
+	}
+	return "", false
+}
+
+// getTimeBucketRange returns first and last buckets by bounds of filters by the time field
+func (params *ListParams) getTimeBucketRange(field string, bucket TimeBucket) (from, to time.Time, hasFrom, hasTo bool) {
+	location := params.GetTimezone()
+	for _, filter := range params.Filters {
+		if filter.Field != field {
+			continue
+		}
+		values, err := params.GetFilterValues(&filter)
+		if err != nil || len(values) != 1 {
+			continue
+		}
+		value, ok := values[0].(time.Time)
+		if !ok {
+			continue
+		}
+		value = value.In(location)
+		switch filter.Operator {
+		case OperatorGt, OperatorGte:
+			from, hasFrom = truncateTime(value, bucket), true
+		case OperatorLt:
+			to, hasTo = truncateTime(value.Add(-time.Nanosecond), bucket), true
+		case OperatorLte:
+			to, hasTo = truncateTime(value, bucket), true
+		case OperatorEq:
+			from, to, hasFrom, hasTo = truncateTime(value, bucket), truncateTime(value, bucket), true, true
+		}
+	}
+	return
+}
+
+// getSeriesKey returns key of values of groups except the time group
+func (params *ListParams) getSeriesKey(groups map[string]interface{}, timeField string) string {
+	parts := make([]string, 0, len(params.Groups))
+	for _, group := range params.Groups {
+		if field, _, _ := parseGroup(group); field != timeField {
+			parts = append(parts, fmt.Sprintf("%v", groups[field]))
+		}
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// newEmptyAggregateRow returns row of the bucket without records
+func (params *ListParams) newEmptyAggregateRow(groups map[string]interface{}, timeField string, value time.Time) AggregateRow {
+	row := AggregateRow{
+		Groups:     make(map[string]interface{}, len(groups)+1),
+		Aggregates: make(map[string]interface{}, len(params.Aggregates)),
+	}
+	for k, v := range groups {
+		row.Groups[k] = v
+	}
+	row.Groups[timeField] = value
+	for _, aggregate := range params.Aggregates {
+		switch aggregate.Func {
+		case AggregateCount, AggregateSum:
+			row.Aggregates[aggregate.Alias] = zeroAggregateValue(params.getAggregateType(&aggregate))
+		default:
+			row.Aggregates[aggregate.Alias] = nil
+		}
+	}
+	return row
+}
+
+func (params *ListParams) isSortedDesc(field string) bool {
+	for _, sorting := range params.Sortings {
+		if sorting.Field == field {
+			return sorting.isDescDirection()
+		}
+	}
+	return false
+}
+
+// parseGroup parses group in format field or field:bucket. Returns false for unknown bucket
+func parseGroup(group string) (string, TimeBucket, bool) {
+	parts := strings.SplitN(group, operatorDelimiter, 2)
+	if len(parts) == 1 {
+		return group, "", true
+	}
+	bucket, ok := knownTimeBuckets[parts[1]]
+	return parts[0], bucket, ok
+}
+
+// parseBucketTime returns start of bucket scanned from database as time in the location.
+// Databases return buckets as local time without timezone
+func parseBucketTime(value interface{}, location *time.Location) interface{} {
+	if bytes, ok := value.([]byte); ok {
+		value = string(bytes)
+	}
+	switch v := value.(type) {
+	case time.Time:
+		return time.Date(v.Year(), v.Month(), v.Day(), v.Hour(), v.Minute(), v.Second(), v.Nanosecond(), location)
+	case string:
+		for _, layout := range bucketTimeLayouts {
+			if result, err := time.ParseInLocation(layout, v, location); err == nil {
+				return result
+			}
+		}
+	}
+	return value
+}
+
+// truncateTime returns start of the bucket containing the time. Weeks start on Monday
+func truncateTime(value time.Time, bucket TimeBucket) time.Time {
+	year, month, day := value.Date()
+	location := value.Location()
+	switch bucket {
+	case BucketHour:
+		return time.Date(year, month, day, value.Hour(), 0, 0, 0, location)
+	case BucketWeek:
+		weekday := (int(value.Weekday()) + 6) % 7
+		return time.Date(year, month, day-weekday, 0, 0, 0, 0, location)
+	case BucketMonth:
+		return time.Date(year, month, 1, 0, 0, 0, 0, location)
+	case BucketYear:
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, location)
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, location)
+}
+
+// nextBucket returns start of the bucket following the bucket
+func nextBucket(value time.Time, bucket TimeBucket) time.Time {
+	switch bucket {
+	case BucketHour:
+		return value.Add(time.Hour)
+	case BucketWeek:
+		return value.AddDate(0, 0, 7)
+	case BucketMonth:
+		return value.AddDate(0, 1, 0)
+	case BucketYear:
+		return value.AddDate(1, 0, 0)
+	}
+	return value.AddDate(0, 0, 1)
+}
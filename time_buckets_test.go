@@ -0,0 +1,87 @@
+package list_params
+
+import (
+	"testing"
+	"time"
+)
+
+const timeBucketsQuery = "group=created_at:day&aggregate=count(*),sum(amount)" +
+	"&filter[created_at:gte]=2024-01-01&filter[created_at:lt]=2024-01-05"
+
+func TestFillTimeBucketsFillsGapsBetweenFilterBounds(t *testing.T) {
+	params := NewListParamsFromQuery(timeBucketsQuery, transaction{})
+	day := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	rows := []AggregateRow{{
+		Groups:     map[string]interface{}{"created_at": day},
+		Aggregates: map[string]interface{}{"count": int64(2), "sum_amount": int64(30)},
+	}}
+
+	filled, err := params.FillTimeBuckets(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filled) != 4 {
+		t.Fatalf("expected 4 rows, got %d", len(filled))
+	}
+	for i, row := range filled {
+		expected := time.Date(2024, 1, 1+i, 0, 0, 0, 0, time.UTC)
+		if value := row.Groups["created_at"].(time.Time); !value.Equal(expected) {
+			t.Errorf("row %d: expected bucket %s, got %s", i, expected, value)
+		}
+		if row.Groups["created_at"].(time.Time).Equal(day) {
+			continue
+		}
+		if row.Aggregates["count"] != int64(0) || row.Aggregates["sum_amount"] != int64(0) {
+			t.Errorf("row %d: expected zero aggregates, got %v", i, row.Aggregates)
+		}
+	}
+}
+
+func TestFillTimeBucketsDescending(t *testing.T) {
+	params := NewListParamsFromQuery(timeBucketsQuery+"&sort=-created_at", transaction{})
+
+	filled, err := params.FillTimeBuckets(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filled) != 4 || !filled[0].Groups["created_at"].(time.Time).Equal(time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected 4 rows from the last bucket, got %v", filled)
+	}
+}
+
+func TestFillTimeBucketsSkipsPagesAndHavings(t *testing.T) {
+	for _, query := range []string{timeBucketsQuery + "&page[size]=2", timeBucketsQuery + "&having[count:gt]=1"} {
+		params := NewListParamsFromQuery(query, transaction{})
+		filled, err := params.FillTimeBuckets([]AggregateRow{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(filled) != 0 {
+			t.Errorf("%s: expected rows as is, got %v", query, filled)
+		}
+	}
+}
+
+func TestFillTimeBucketsInTimezone(t *testing.T) {
+	params := NewListParamsFromQuery(timeBucketsQuery+"&tz=Europe/Madrid", transaction{})
+	if len(params.errors) != 0 {
+		t.Fatal(params.errors)
+	}
+
+	filled, err := params.FillTimeBuckets(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// bounds of filters are in UTC, 2024-01-05 00:00 UTC is in the bucket of 2024-01-05 in Madrid
+	location := params.GetTimezone()
+	if len(filled) != 5 || !filled[0].Groups["created_at"].(time.Time).Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, location)) {
+		t.Errorf("expected 5 buckets from midnight in %s, got %v", location, filled)
+	}
+}
+
+func TestLocalTimezoneIsRejected(t *testing.T) {
+	params := NewListParamsFromQuery("tz=Local", transaction{})
+	if errs := params.errors; len(errs) != 1 || AsParamError(errs[0]).Code != CodeTimezoneInvalid {
+		t.Errorf("expected %s error, got %v", CodeTimezoneInvalid, errs)
+	}
+}
@@ -0,0 +1,174 @@
+package list_params
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	RuleType      = "type"
+	RuleEnum      = "enum"
+	RulePattern   = "pattern"
+	RuleMin       = "min"
+	RuleMax       = "max"
+	RuleMaxLength = "max_length"
+	RuleMaxValues = "max_values"
+	RuleCustom    = "custom"
+)
+
+// ValueValidator checks values passed to the filter.
+// Should return *RuleError, any other error is treated as violation of RuleCustom
+type ValueValidator func(values []string) error
+
+// RuleError describes violation of value validation rule.
+// Args are used in message templates, see Messages
+type RuleError struct {
+	Rule  string
+	Value string
+	Args  map[string]string
+}
+
+func (e *RuleError) Error() string {
+	return fmt.Sprintf("Value %s violates rule %s", e.Value, e.Rule)
+}
+
+type filterValidator struct {
+	Field      string
+	Validators []ValueValidator
+}
+
+// Enum allows only passed values
+func Enum(allowed ...string) ValueValidator {
+	return Each(func(value string) error {
+		for _, v := range allowed {
+			if v == value {
+				return nil
+			}
+		}
+		return newRuleError(RuleEnum, value, map[string]string{"allowed": strings.Join(allowed, ", ")})
+	})
+}
+
+// Pattern allows only values matching passed regular expression
+func Pattern(pattern string) ValueValidator {
+	expr := regexp.MustCompile(pattern)
+	return Each(func(value string) error {
+		if !expr.MatchString(value) {
+			return newRuleError(RulePattern, value, map[string]string{"pattern": pattern})
+		}
+		return nil
+	})
+}
+
+// Min allows only numbers greater than or equal to min
+func Min(min float64) ValueValidator {
+	return Each(func(value string) error {
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil || number < min {
+			return newRuleError(RuleMin, value, map[string]string{"min": formatNumber(min)})
+		}
+		return nil
+	})
+}
+
+// Max allows only numbers less than or equal to max
+func Max(max float64) ValueValidator {
+	return Each(func(value string) error {
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil || number > max {
+			return newRuleError(RuleMax, value, map[string]string{"max": formatNumber(max)})
+		}
+		return nil
+	})
+}
+
+// MaxLength limits length of each value in characters
+func MaxLength(length int) ValueValidator {
+	return Each(func(value string) error {
+		if utf8.RuneCountInString(value) > length {
+			return newRuleError(RuleMaxLength, value, map[string]string{"max_length": strconv.Itoa(length)})
+		}
+		return nil
+	})
+}
+
+// MaxValues limits count of passed values. Useful for in and nin operators
+func MaxValues(count int) ValueValidator {
+	return func(values []string) error {
+		if len(values) > count {
+			return newRuleError(RuleMaxValues, strings.Join(values, queryParamDelimiter),
+				map[string]string{"max_values": strconv.Itoa(count)})
+		}
+		return nil
+	}
+}
+
+// Each creates ValueValidator which calls passed function for every value
+func Each(function func(value string) error) ValueValidator {
+	return func(values []string) error {
+		for _, v := range values {
+			if err := function(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// AddFilterValidators adds validators of values for the filter.
+// Validators are called by Validate for every operator of allowed filter
+func (params *ListParams) AddFilterValidators(field string, validators ...ValueValidator) {
+	params.filterValidators = append(params.filterValidators, filterValidator{field, validators})
+}
+
+// validateFilterValues returns first violation of filter values
+func (params *ListParams) validateFilterValues(filter *FilterListParameter) *ParamError {
+	custom := params.getCustomFilter(filter.Field)
+	if custom == nil {
+		if _, err := params.GetFilterValues(filter); err != nil {
+			return newFilterValueError(filter, err)
+		}
+	}
+	for _, v := range params.filterValidators {
+		if v.Field != filter.Field {
+			continue
+		}
+		for _, validator := range v.Validators {
+			if err := validator(filter.Values); err != nil {
+				return newFilterValueError(filter, err)
+			}
+		}
+	}
+	if custom != nil && custom.ContextFunc != nil {
+		if _, _, err := params.getConditionPartFromCustomFilter(custom, filter.Values); err != nil {
+			return newFilterValueError(filter, err)
+		}
+	}
+	return nil
+}
+
+func newRuleError(rule, value string, args map[string]string) *RuleError {
+	return &RuleError{Rule: rule, Value: value, Args: args}
+}
+
+func formatNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
+func newFilterValueError(filter *FilterListParameter, err error) *ParamError {
+	ruleErr, ok := err.(*RuleError)
+	if !ok {
+		ruleErr = newRuleError(RuleCustom, strings.Join(filter.Values, queryParamDelimiter),
+			map[string]string{"reason": err.Error()})
+	}
+	code, ok := ruleCodes[ruleErr.Rule]
+	if !ok {
+		code = CodeFilterValueInvalid
+		ruleErr.Args = map[string]string{"reason": err.Error()}
+	}
+	return newParamError(code, filterParameter(filter.Field, filter.Operator),
+		filter.Field, filter.Operator, ruleErr.Value, ruleErr.Args)
+}
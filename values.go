@@ -0,0 +1,199 @@
+package list_params
+
+import (
+	"database/sql"
+	"encoding"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iancoleman/strcase"
+)
+
+const dateLayout = "2006-01-02"
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	scannerType         = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+var timeLayouts = []string{time.RFC3339Nano, time.RFC3339, dateLayout}
+
+// ConvertFilterValue converts passed string value to the value of given type.
+// Supports ints, uints, floats, bools, time.Time in RFC3339 or date-only format,
+// UUIDs and any type implements encoding.TextUnmarshaler or sql.Scanner.
+// Values of unknown types are returned as is
+func ConvertFilterValue(valueType reflect.Type, value string) (interface{}, error) {
+	for valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+
+	if valueType == timeType {
+		return parseTime(value)
+	}
+	if reflect.PtrTo(valueType).Implements(textUnmarshalerType) {
+		result := reflect.New(valueType)
+		if err := result.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value)); err != nil {
+			return nil, err
+		}
+		return result.Elem().Interface(), nil
+	}
+	if reflect.PtrTo(valueType).Implements(scannerType) {
+		result := reflect.New(valueType)
+		if err := result.Interface().(sql.Scanner).Scan(value); err != nil {
+			return nil, err
+		}
+		return result.Elem().Interface(), nil
+	}
+
+	switch valueType.Kind() {
+	case reflect.Bool:
+		return strconv.ParseBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(value, 10, valueType.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(value, 10, valueType.Bits())
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(value, valueType.Bits())
+	case reflect.Array:
+		if valueType.Len() == 16 && valueType.Elem().Kind() == reflect.Uint8 {
+			return parseUUID(valueType, value)
+		}
+	}
+	return value, nil
+}
+
+// GetFilterValues returns values of the filter converted to types of model fields.
+// Values of like filters and of fields which are not found in model are returned as strings.
+// Returns *RuleError if some value can not be converted
+func (params *ListParams) GetFilterValues(filter *FilterListParameter) ([]interface{}, error) {
+	result := make([]interface{}, len(filter.Values))
+	fieldType, ok := params.getFieldType(filter.Field)
+	if _, isCount := params.getCountRelationName(filter.Field); isCount {
+		fieldType, ok = countType, true
+	}
+	for i, v := range filter.Values {
+		if !ok || filter.Operator == OperatorLike {
+			result[i] = v
+			continue
+		}
+		converted, err := ConvertFilterValue(fieldType, v)
+		if err != nil {
+			return nil, newRuleError(RuleType, v, map[string]string{"type": typeDescription(fieldType)})
+		}
+		result[i] = converted
+	}
+	return result, nil
+}
+
+// getFieldType returns type of model field found by presented name.
+// Nested fields are looked up through relationships: [relationship name].[field name]
+func (params *ListParams) getFieldType(presentedName string) (reflect.Type, bool) {
+	field, ok := params.getStructField(presentedName)
+	if !ok {
+		return nil, false
+	}
+	return field.Type, true
+}
+
+// getStructField returns struct field of the model or its relation by presented name
+func (params *ListParams) getStructField(presentedName string) (reflect.StructField, bool) {
+	field, _, ok := params.findStructFieldPath(presentedName)
+	return field, ok
+}
+
+// getStructFieldPath returns path of struct field names by presented path of json names or field names
+func (params *ListParams) getStructFieldPath(presentedName string) (string, bool) {
+	_, path, ok := params.findStructFieldPath(presentedName)
+	return path, ok
+}
+
+func (params *ListParams) findStructFieldPath(presentedName string) (reflect.StructField, string, bool) {
+	var field reflect.StructField
+	if params.ObjectType == nil {
+		return field, "", false
+	}
+	modelType := params.ObjectType
+	parts := strings.Split(presentedName, sqlTableFieldDelimiter)
+	names := make([]string, 0, len(parts))
+	for _, part := range parts {
+		for modelType.Kind() == reflect.Ptr || modelType.Kind() == reflect.Slice {
+			modelType = modelType.Elem()
+		}
+		if modelType.Kind() != reflect.Struct {
+			return field, "", false
+		}
+		var ok bool
+		if field, ok = findStructField(modelType, part); !ok {
+			return field, "", false
+		}
+		names = append(names, field.Name)
+		modelType = field.Type
+	}
+	return field, strings.Join(names, includesSeparator), true
+}
+
+// findStructField looks for struct field by json tag or by name
+func findStructField(modelType reflect.Type, presentedName string) (reflect.StructField, bool) {
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		if jsonName(field) == presentedName {
+			return field, true
+		}
+	}
+	return modelType.FieldByName(strcase.ToCamel(presentedName))
+}
+
+// jsonName returns name of struct field from json tag without options
+func jsonName(field reflect.StructField) string {
+	return strings.Split(field.Tag.Get("json"), ",")[0]
+}
+
+// typeDescription returns human readable name of value type
+func typeDescription(valueType reflect.Type) string {
+	for valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+	if valueType == timeType {
+		return "date or RFC3339 time"
+	}
+	switch valueType.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	return valueType.Name()
+}
+
+func parseTime(value string) (time.Time, error) {
+	var err error
+	for _, layout := range timeLayouts {
+		var result time.Time
+		if result, err = time.Parse(layout, value); err == nil {
+			return result, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+func parseUUID(valueType reflect.Type, value string) (interface{}, error) {
+	raw := strings.Replace(value, "-", "", -1)
+	if len(raw) != 32 || (len(value) != 32 && len(value) != 36) {
+		return nil, fmt.Errorf("invalid UUID %s", value)
+	}
+	bytes, err := hex.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID %s", value)
+	}
+	result := reflect.New(valueType).Elem()
+	reflect.Copy(result, reflect.ValueOf(bytes))
+	return result.Interface(), nil
+}